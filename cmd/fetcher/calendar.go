@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+func runCalendar(args []string) int {
+	flags := newFlagSet("calendar", "[flags]", "Resolves the merged namedays and movable feasts into the dates of one year,\nwith old style dates.")
+	dataDir := flags.String("data-dir", defaultDataDir, "Directory with namedays files")
+	in := flags.String("in", "", "Input dataset (default <data-dir>/merged_namedays.json)")
+	out := flags.String("out", "", "Output file, \"-\" for stdout (default <data-dir>/calendar_<year>.json)")
+	year := flags.Int("year", time.Now().Year(), "Year to build the calendar for")
+	feastsFile := flags.String("feasts", "", "JSON file with movable feasts, the built-in list is used if empty")
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	if *in == "" {
+		*in = filepath.Join(*dataDir, mergedFile)
+	}
+	if *out == "" {
+		*out = filepath.Join(*dataDir, fmt.Sprintf("calendar_%d.json", *year))
+	}
+
+	calendar, err := buildYearCalendar(*in, *year, *feastsFile)
+	if err != nil {
+		return fail("error building calendar: %v", err)
+	}
+
+	if err := writeJSON(*out, calendar); err != nil {
+		return fail("%v", err)
+	}
+
+	log.Printf("Successfully built the %d calendar and saved to %s", *year, *out)
+	return exitOK
+}
+
+// buildYearCalendar resolves the merged namedays and movable feasts into the
+// dates of a particular year
+func buildYearCalendar(input string, year int, feastsFile string) (domain.YearCalendar, error) {
+	namedays, err := loadNamedays(input)
+	if err != nil {
+		return domain.YearCalendar{}, err
+	}
+
+	feasts, err := loadFeasts(feastsFile)
+	if err != nil {
+		return domain.YearCalendar{}, err
+	}
+
+	return domain.BuildYearCalendar(year, namedays, feasts)
+}
+
+// loadFeasts reads movable feasts from a JSON file, or returns the
+// built-in list if path is empty
+func loadFeasts(path string) ([]domain.MovableFeast, error) {
+	if path == "" {
+		return domain.DefaultMovableFeasts(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %v", path, err)
+	}
+	return domain.ParseMovableFeasts(data)
+}
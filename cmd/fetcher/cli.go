@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/fetch"
+	"github.com/kvloginov/namedays/internal/names"
+)
+
+// Exit codes shared by all commands
+const (
+	// exitOK means the command succeeded and found nothing to report
+	exitOK = 0
+	// exitFindings means the command worked but its answer is "no": the
+	// datasets differ, validation failed or a query found nothing
+	exitFindings = 1
+	// exitError means bad usage or a failure such as a missing file
+	exitError = 2
+)
+
+const (
+	defaultDataDir = "data"
+	mergedFile     = "merged_namedays.json"
+)
+
+// newFlagSet creates the flag set of a command with a usage message that
+// shows the given argument synopsis
+func newFlagSet(name, synopsis, description string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		out := flags.Output()
+		fmt.Fprintf(out, "Usage: fetcher %s %s\n\n%s\n", name, synopsis, description)
+
+		hasFlags := false
+		flags.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintf(out, "\nFlags:\n")
+			flags.PrintDefaults()
+		}
+	}
+	return flags
+}
+
+// parseFlags parses args and returns the exit code to stop with, if any:
+// exitOK for -h/--help and exitError for invalid flags
+func parseFlags(flags *flag.FlagSet, args []string) (int, bool) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, true
+		}
+		return exitError, true
+	}
+	return 0, false
+}
+
+// fail reports an error and returns exitError
+func fail(format string, args ...any) int {
+	fmt.Fprintf(os.Stderr, "error: "+format+"\n", args...)
+	return exitError
+}
+
+// signalContext returns a context cancelled on Ctrl+C and, if timeout is
+// positive, after timeout
+func signalContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	if timeout <= 0 {
+		return ctx, stop
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// datasetFiles returns the files of all registered sources and the merged
+// file that exist in dataDir
+func datasetFiles(dataDir string) []string {
+	var files []string
+	for _, s := range fetch.Sources() {
+		files = append(files, filepath.Join(dataDir, s.OutputFile))
+	}
+	files = append(files, filepath.Join(dataDir, mergedFile))
+
+	existing := files[:0]
+	for _, file := range files {
+		if _, err := os.Stat(file); err == nil {
+			existing = append(existing, file)
+		}
+	}
+	return existing
+}
+
+// loadNamedays reads a NamedaysDataList JSON file
+func loadNamedays(path string) (domain.NamedaysDataList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %v", path, err)
+	}
+
+	var namedays domain.NamedaysDataList
+	if err := json.Unmarshal(data, &namedays); err != nil {
+		return nil, fmt.Errorf("error unmarshalling file %s: %v", path, err)
+	}
+
+	return namedays, nil
+}
+
+// loadNormalizer loads the name variant dictionary. An empty path means
+// no dictionary: only ё and case are folded.
+func loadNormalizer(path string) (*names.Normalizer, error) {
+	if path == "" {
+		return names.NewNormalizer(nil), nil
+	}
+
+	dict, err := names.LoadDictionary(path)
+	if err != nil {
+		return nil, err
+	}
+	return names.NewNormalizer(dict), nil
+}
+
+// namesDictUsage is the help text of the -names-dict flag
+const namesDictUsage = "Name variants dictionary (default <data-dir>/" + names.DictionaryFile + ", \"none\" to disable)"
+
+// dictionaryPath resolves the -names-dict value: empty means the
+// dictionary in the data directory and "none" means no dictionary
+func dictionaryPath(flagValue, dataDir string) string {
+	switch flagValue {
+	case "":
+		return filepath.Join(dataDir, names.DictionaryFile)
+	case "none":
+		return ""
+	default:
+		return flagValue
+	}
+}
+
+// genderUsage is the help text of the -gender flag
+const genderUsage = "Only names of this gender: male or female, inferred from the names dictionary\nand the ending of the name where the sources don't give it"
+
+// parseGenderFlag parses the -gender value, empty means any gender
+func parseGenderFlag(value string) (domain.Gender, error) {
+	if value == "" {
+		return domain.GenderUnknown, nil
+	}
+	return domain.ParseGender(value)
+}
+
+// writeOutput writes data to path, or to stdout if path is "-"
+func writeOutput(path string, write func(io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating file %s: %v", path, err)
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing file %s: %v", path, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing file %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// writeJSON saves v as compact JSON to path, or to stdout if path is "-"
+func writeJSON(path string, v any) error {
+	return writeOutput(path, func(w io.Writer) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/diff"
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/fetch"
+)
+
+// runDiff compares two namedays files, or a fresh scrape of a source with
+// its committed file. Like diff(1) it returns exitOK when the datasets
+// match and exitFindings when they differ.
+func runDiff(args []string) int {
+	flags := newFlagSet("diff", "[flags] A.json B.json\n       fetcher diff [flags] -fresh <source>",
+		"Reports, per date, the names only in A, the names only in B and the dates\nmissing from either. With -fresh, A is the committed file of the source\nand B a fresh scrape of it.")
+	dataDir := flags.String("data-dir", defaultDataDir, "Directory with namedays files, used with -fresh")
+	out := flags.String("out", "-", "Output file, \"-\" for stdout")
+	format := flags.String("format", "text", "Output format: text, json or markdown")
+	fresh := flags.String("fresh", "", "Scrape this source and compare it with its committed file")
+	cacheMode := flags.String("cache", "off", "HTTP response cache mode for -fresh: readwrite, readonly (offline) or off")
+	cacheDir := flags.String("cache-dir", ".cache/http", "Directory for cached HTTP responses")
+	timeout := flags.Duration("timeout", 0, "Overall deadline for -fresh, e.g. 10m (0 means no deadline)")
+	namesDict := flags.String("names-dict", "", namesDictUsage)
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	outputFormat, err := diff.ParseFormat(*format)
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	var a, b domain.NamedaysDataList
+	var nameA, nameB string
+
+	switch {
+	case *fresh != "" && flags.NArg() == 0:
+		mode, err := fetch.ParseCacheMode(*cacheMode)
+		if err != nil {
+			return fail("%v", err)
+		}
+
+		fetcher, filename, err := newFetcher(*fresh, fetch.WithCache(*cacheDir, mode), fetch.WithProgressOutput(os.Stderr))
+		if err != nil {
+			return fail("%v", err)
+		}
+
+		nameA = filepath.Join(*dataDir, filename)
+		if a, err = loadNamedays(nameA); err != nil {
+			return fail("%v", err)
+		}
+
+		ctx, cancel := signalContext(*timeout)
+		defer cancel()
+
+		if b, err = fetcher.FetchAllNamedays(ctx); err != nil {
+			return reportFetchError(*fresh, b, err)
+		}
+		nameB = fmt.Sprintf("fresh %s (%s)", *fresh, time.Now().Format(time.DateOnly))
+	case *fresh == "" && flags.NArg() == 2:
+		nameA, nameB = flags.Arg(0), flags.Arg(1)
+		if a, err = loadNamedays(nameA); err != nil {
+			return fail("%v", err)
+		}
+		if b, err = loadNamedays(nameB); err != nil {
+			return fail("%v", err)
+		}
+	default:
+		flags.Usage()
+		return exitError
+	}
+
+	normalizer, err := loadNormalizer(dictionaryPath(*namesDict, *dataDir))
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	report := diff.Compare(a, b, normalizer)
+	err = writeOutput(*out, func(w io.Writer) error {
+		return report.Write(w, outputFormat, nameA, nameB)
+	})
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	if !report.Empty() {
+		return exitFindings
+	}
+	return exitOK
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/ical"
+	"github.com/kvloginov/namedays/internal/query"
+	"github.com/kvloginov/namedays/internal/translit"
+)
+
+func runExport(args []string) int {
+	flags := newFlagSet("export", "[flags] [name...]", "Writes a namedays dataset in another format.\n\nThe ics format writes a calendar with a yearly all-day event for every\nnameday of the given names, which can be read from -names-file as well.\nNames may be typed in Latin script, like Ksenia or Aleksandr.\n\nWith -year the namedays are resolved into the dates of that year first:\nFeb 29 moves to Feb 28 in non-leap years, the movable feasts are added and\nevery date gets its old style date. The ics events then happen once, on\nthe dates of that year, instead of every year.")
+	dataDir := flags.String("data-dir", defaultDataDir, "Directory with namedays files")
+	in := flags.String("in", "", "Input dataset (default <data-dir>/merged_namedays.json)")
+	out := flags.String("out", "-", "Output file, \"-\" for stdout")
+	format := flags.String("format", "csv", "Output format: csv, markdown, json or ics")
+	namesDict := flags.String("names-dict", "", namesDictUsage)
+	namesFile := flags.String("names-file", "", "File with names to export as ics, one per line")
+	calendarName := flags.String("calendar-name", "Именины", "Calendar title (ics only)")
+	remind := flags.Duration("remind", 0, "Add a reminder this long before the nameday starts, e.g. 15h for 9:00 the day before (ics only)")
+	genderFlag := flags.String("gender", "", genderUsage)
+	year := flags.Int("year", 0, "Export the calendar of this year rather than the fixed dates")
+	feastsFile := flags.String("feasts", "", "JSON file with movable feasts for -year, the built-in list is used if empty")
+	latin := flags.String("latin", "", "Write the names in Latin script: gost (GOST 7.79), icao (passports) or informal")
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	if *format != "ics" && flags.NArg() != 0 {
+		flags.Usage()
+		return exitError
+	}
+	if *in == "" {
+		*in = filepath.Join(*dataDir, mergedFile)
+	}
+	gender, err := parseGenderFlag(*genderFlag)
+	if err != nil {
+		return fail("%v", err)
+	}
+	var scheme translit.Scheme
+	if *latin != "" {
+		if scheme, err = translit.ParseScheme(*latin); err != nil {
+			return fail("%v", err)
+		}
+	}
+	normalizer, err := loadNormalizer(dictionaryPath(*namesDict, *dataDir))
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	var write func(io.Writer, domain.NamedaysDataList) error
+	switch *format {
+	case "csv":
+		write = exportCSV
+	case "markdown", "md":
+		write = exportMarkdown
+	case "json":
+		write = func(w io.Writer, namedays domain.NamedaysDataList) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(namedays)
+		}
+	case "ics":
+		wanted := flags.Args()
+		if *namesFile != "" {
+			fromFile, err := readNameList(*namesFile)
+			if err != nil {
+				return fail("%v", err)
+			}
+			wanted = append(wanted, fromFile...)
+		}
+		if len(wanted) == 0 {
+			return fail("no names to export, pass them as arguments or with -names-file")
+		}
+
+		calendar := ical.Calendar{Name: *calendarName, Reminder: *remind, Stamp: time.Now(), Year: *year}
+		write = func(w io.Writer, namedays domain.NamedaysDataList) error {
+			events := ical.EventsFor(query.New(namedays, normalizer), wanted)
+			if scheme != "" {
+				for i := range events {
+					events[i].Title = scheme.Transliterate(events[i].Name)
+				}
+			}
+			return calendar.Write(w, events)
+		}
+	default:
+		return fail("unknown format: %s (expected csv, markdown, json or ics)", *format)
+	}
+
+	namedays, err := loadNamedays(*in)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if *year != 0 {
+		feasts, err := loadFeasts(*feastsFile)
+		if err != nil {
+			return fail("%v", err)
+		}
+		calendar, err := domain.BuildYearCalendar(*year, namedays, feasts)
+		if err != nil {
+			return fail("error building calendar: %v", err)
+		}
+		namedays = calendar.Days
+	}
+	if gender != domain.GenderUnknown {
+		namedays = namedays.Filter(func(n domain.NamedaysData, name string) bool {
+			return normalizer.GenderIn(n, name) == gender
+		})
+	}
+
+	// The calendar looks names up in the original script and writes its
+	// events in Latin itself
+	if scheme != "" && *format != "ics" {
+		namedays = scheme.Namedays(namedays)
+	}
+
+	err = writeOutput(*out, func(w io.Writer) error {
+		return write(w, namedays)
+	})
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	return exitOK
+}
+
+// readNameList reads one name per line, skipping blank lines and # comments
+func readNameList(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %v", path, err)
+	}
+
+	var result []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			result = append(result, line)
+		}
+	}
+	return result, nil
+}
+
+// exportCSV writes one row per date and name
+func exportCSV(w io.Writer, namedays domain.NamedaysDataList) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"date", "name"}); err != nil {
+		return err
+	}
+
+	for _, n := range namedays {
+		for _, name := range n.Names {
+			if err := cw.Write([]string{n.Date.String(), name}); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportMarkdown writes a table with one row per date
+func exportMarkdown(w io.Writer, namedays domain.NamedaysDataList) error {
+	if _, err := io.WriteString(w, "| Дата | Имена |\n| --- | --- |\n"); err != nil {
+		return err
+	}
+
+	for _, n := range namedays {
+		if _, err := fmt.Fprintf(w, "| %s | %s |\n", n.DateLabel(), strings.Join(n.Names, ", ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
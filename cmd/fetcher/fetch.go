@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/fetch"
+)
+
+func runFetch(args []string) int {
+	flags := newFlagSet("fetch", "[flags] <source>", "Scrapes namedays from a source and saves them as JSON.\n\nSources:\n"+describeSources())
+	dataDir := flags.String("data-dir", defaultDataDir, "Directory with namedays files")
+	out := flags.String("out", "", "Output file, \"-\" for stdout (default <data-dir>/<source>_namedays.json)")
+	concurrency := flags.Int("concurrency", 4, "Number of pages fetched in parallel (calend only)")
+	rps := flags.Float64("rps", 2, "Maximum requests per second to the source host, 0 disables the limit")
+	burst := flags.Int("burst", 4, "Maximum burst of requests above the rate limit")
+	retries := flags.Int("retries", 3, "Number of retries for transient HTTP failures")
+	retryDelay := flags.Duration("retry-delay", 500*time.Millisecond, "Initial delay between retries, doubled on every attempt")
+	retryMaxDelay := flags.Duration("retry-max-delay", 30*time.Second, "Maximum delay between retries")
+	cacheMode := flags.String("cache", "off", "HTTP response cache mode: readwrite, readonly (offline) or off")
+	cacheDir := flags.String("cache-dir", ".cache/http", "Directory for cached HTTP responses")
+	timeout := flags.Duration("timeout", 0, "Overall deadline for fetching, e.g. 10m (0 means no deadline)")
+	reportFile := flags.String("report", "", "Write a JSON report of how the source was parsed, e.g. which strategy produced each date (pravmir only)")
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitError
+	}
+	source := flags.Arg(0)
+
+	mode, err := fetch.ParseCacheMode(*cacheMode)
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	fetcher, filename, err := newFetcher(source,
+		fetch.WithCache(*cacheDir, mode),
+		fetch.WithConcurrency(*concurrency),
+		fetch.WithRateLimit(*rps, *burst),
+		fetch.WithRetryPolicy(fetch.RetryPolicy{
+			MaxRetries: *retries,
+			BaseDelay:  *retryDelay,
+			MaxDelay:   *retryMaxDelay,
+		}),
+		// Keep stdout clean for -out -
+		fetch.WithProgressOutput(os.Stderr),
+	)
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	if *out == "" {
+		*out = filepath.Join(*dataDir, filename)
+	}
+	if _, ok := fetcher.(fetch.Reporter); !ok && *reportFile != "" {
+		return fail("source %s doesn't write a report", source)
+	}
+
+	// Stop cleanly on Ctrl+C instead of leaving a half-written file behind
+	ctx, cancel := signalContext(*timeout)
+	defer cancel()
+
+	var namedays domain.NamedaysDataList
+	if reporter, ok := fetcher.(fetch.Reporter); ok {
+		var report fetch.Report
+		namedays, report, err = reporter.FetchWithReport(ctx)
+		if err == nil {
+			err = saveReport(*reportFile, report)
+		}
+	} else {
+		namedays, err = fetcher.FetchAllNamedays(ctx)
+	}
+	if err != nil {
+		return reportFetchError(source, namedays, err)
+	}
+
+	if err := writeJSON(*out, namedays); err != nil {
+		return fail("%v", err)
+	}
+
+	log.Printf("Successfully fetched namedays from %s and saved to %s", source, *out)
+	return exitOK
+}
+
+// saveReport logs the summary of a fetch report and writes the full
+// report to path, if set
+func saveReport(path string, report fetch.Report) error {
+	log.Printf("Fetch report: %s", report.Summary())
+
+	if path == "" {
+		return nil
+	}
+	return writeJSON(path, report)
+}
+
+func runSources(args []string) int {
+	flags := newFlagSet("sources", "", "Lists the registered namedays sources.")
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	fmt.Println(describeSources())
+	return exitOK
+}
+
+// newFetcher creates the fetcher for a registered source and returns the
+// name of the file its results are saved to in the data directory
+func newFetcher(name string, opts ...fetch.Option) (fetch.Fetcher, string, error) {
+	source, ok := fetch.Lookup(name)
+	if !ok {
+		return nil, "", fmt.Errorf("unknown source: %s (expected one of %s)", name, strings.Join(sourceNames(), ", "))
+	}
+	return source.New(opts...), source.OutputFile, nil
+}
+
+// sourceNames returns the names of all registered sources
+func sourceNames() []string {
+	var result []string
+	for _, s := range fetch.Sources() {
+		result = append(result, s.Name)
+	}
+	return result
+}
+
+// describeSources lists the registered sources for help messages
+func describeSources() string {
+	var b strings.Builder
+	for _, s := range fetch.Sources() {
+		fmt.Fprintf(&b, "  %-12s %s (%s, saved to %s)\n", s.Name, s.Description, s.Locale, s.OutputFile)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// reportFetchError reports a failed fetch. When the fetch was interrupted
+// or ran out of time, it also reports how far it got.
+func reportFetchError(source string, partial []domain.NamedaysData, err error) int {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return fail("fetching %s stopped after %d dates, nothing was saved: %v", source, len(partial), err)
+	}
+	return fail("error fetching namedays: %v", err)
+}
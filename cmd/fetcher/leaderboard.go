@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/kvloginov/namedays/internal/query"
+	"github.com/kvloginov/namedays/internal/scoring"
+)
+
+func runLeaderboard(args []string) int {
+	flags := newFlagSet("leaderboard", "[flags] <roster|link>", "Ranks the people of a roster file (YAML or JSON) or a shared link by their\nnamedays from January 1 to a date: 1 point for their own nameday, 0.5 for\neach parent's and 0.25 for each grandparent's. A short form like Саша\nscores one of its full names: the one of the person's gender, if the roster\ngives it, else the first, marked with a question mark.")
+	dataDir := flags.String("data-dir", defaultDataDir, "Directory with namedays files")
+	in := flags.String("in", "", "Input dataset (default <data-dir>/merged_namedays.json)")
+	namesDict := flags.String("names-dict", "", namesDictUsage)
+	date := flags.String("date", "today", "Last day of the competition, YYYY-MM-DD or \"today\"")
+	out := flags.String("out", "-", "Output file, \"-\" for stdout")
+	format := flags.String("format", "table", "Output format: table, json or csv")
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitError
+	}
+	outputFormat, err := scoring.ParseFormat(*format)
+	if err != nil {
+		return fail("%v", err)
+	}
+	now, err := parseQueryDate(*date)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if *in == "" {
+		*in = filepath.Join(*dataDir, mergedFile)
+	}
+
+	r, err := readRoster(flags.Arg(0))
+	if err != nil {
+		return fail("%v", err)
+	}
+	namedays, err := loadNamedays(*in)
+	if err != nil {
+		return fail("%v", err)
+	}
+	normalizer, err := loadNormalizer(dictionaryPath(*namesDict, *dataDir))
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	scorer := scoring.NewScorer(query.New(namedays, normalizer))
+	scorer.IncludeRelatives = r.IncludeRelatives
+	leaderboard := scorer.Leaderboard(r.People, now)
+	for _, s := range leaderboard {
+		if len(s.OtherFullNames) > 0 {
+			log.Printf("%s is scored as %s but may be %s as well, set the gender in the roster to choose",
+				s.Name, s.FullName, strings.Join(s.OtherFullNames, ", "))
+		}
+	}
+
+	err = writeOutput(*out, func(w io.Writer) error {
+		return leaderboard.Write(w, outputFormat)
+	})
+	if err != nil {
+		return fail("%v", err)
+	}
+	return exitOK
+}
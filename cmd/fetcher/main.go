@@ -1,148 +1,87 @@
 package main
 
 import (
-	"encoding/json"
-	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
-	"path/filepath"
-	"sort"
-	"strconv"
-	"strings"
-	"time"
-
-	"github.com/kvloginov/namedays/internal/domain"
-	fetch "github.com/kvloginov/namedays/internal/fetch"
 )
 
-func main() {
-	sourceType := flag.String("source", "krestilnoe", "The source to fetch namedays from (krestilnoe, calend, pravmir, or merge)")
-	flag.Parse()
-
-	var fetcher fetch.Fetcher
-	var filename string
-	var namedays []domain.NamedaysData
-	var err error
-
-	switch *sourceType {
-	case "krestilnoe":
-		fetcher = fetch.NewKrestilnoeFetcher()
-		filename = "data/krestilnoe_namedays.json"
-		namedays, err = fetcher.FetchAllNamedays()
-		if err != nil {
-			log.Fatalf("error fetching namedays: %v", err)
-		}
-	case "calend":
-		fetcher = fetch.NewCalendFetcher()
-		filename = "data/calend_namedays.json"
-		namedays, err = fetcher.FetchAllNamedays()
-		if err != nil {
-			log.Fatalf("error fetching namedays: %v", err)
-		}
-	case "pravmir":
-		fetcher = fetch.NewPravmirFetcher()
-		filename = "data/pravmir_namedays.json"
-		namedays, err = fetcher.FetchAllNamedays()
-		if err != nil {
-			log.Fatalf("error fetching namedays: %v", err)
-		}
-	case "merge":
-		filename = "data/merged_namedays.json"
-		namedays, err = mergeNamedaysFiles()
-		if err != nil {
-			log.Fatalf("error merging namedays: %v", err)
-		}
-	default:
-		log.Fatalf("unknown source type: %s", *sourceType)
-	}
-
-	// save to file
-	jsonData, err := json.Marshal(namedays)
-	if err != nil {
-		log.Fatalf("error marshalling namedays: %v", err)
-	}
+// command is a fetcher subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
 
-	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
-		log.Fatalf("error writing file: %v", err)
+var commands []command
+
+func init() {
+	// Assigned in init because help refers back to the list
+	commands = []command{
+		{"sources", "List the sources that can be fetched", runSources},
+		{"fetch", "Scrape namedays from a source", runFetch},
+		{"merge", "Merge the source files into one dataset", runMerge},
+		{"calendar", "Build the namedays calendar of a particular year", runCalendar},
+		{"diff", "Compare two namedays datasets", runDiff},
+		{"validate", "Check a dataset for completeness and sanity", runValidate},
+		{"query", "Look up namedays by name or date", runQuery},
+		{"export", "Export a dataset in another format", runExport},
+		{"stats", "Show statistics about the datasets", runStats},
+		{"roster", "Create, check and share rosters of people", runRoster},
+		{"leaderboard", "Rank a roster of people by their namedays", runLeaderboard},
+		{"serve", "Serve the dataset as a JSON API", runServe},
+		{"help", "Show help for a command", runHelp},
 	}
-
-	fmt.Printf("Successfully fetched namedays from %s and saved to %s\n", *sourceType, filename)
 }
 
-func mergeNamedaysFiles() ([]domain.NamedaysData, error) {
-	// Map to store merged namedays data by date
-	mergedMap := make(map[string]map[string]bool)
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-	// Find all namedays files in data directory
-	files, err := filepath.Glob("data/*_namedays.json")
-	if err != nil {
-		return nil, fmt.Errorf("error finding namedays files: %v", err)
+func run(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return exitError
 	}
 
-	for _, file := range files {
-		// Skip merged_namedays.json if it exists
-		if strings.Contains(file, "merged_namedays.json") {
-			continue
-		}
-
-		// Read file
-		data, err := os.ReadFile(file)
-		if err != nil {
-			return nil, fmt.Errorf("error reading file %s: %v", file, err)
-		}
+	switch args[0] {
+	case "-h", "-help", "--help":
+		printUsage(os.Stdout)
+		return exitOK
+	}
 
-		// Parse JSON
-		var namedaysList []domain.NamedaysData
-		if err := json.Unmarshal(data, &namedaysList); err != nil {
-			return nil, fmt.Errorf("error unmarshalling file %s: %v", file, err)
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
 		}
+	}
 
-		// Merge data
-		for _, nameday := range namedaysList {
-			date := nameday.Date.String()
-
-			// Initialize map for this date if not exists
-			if _, ok := mergedMap[date]; !ok {
-				mergedMap[date] = make(map[string]bool)
-			}
+	fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", args[0])
+	printUsage(os.Stderr)
+	return exitError
+}
 
-			// Add names for this date
-			for _, name := range nameday.Names {
-				mergedMap[date][name] = true
-			}
-		}
+func runHelp(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stdout)
+		return exitOK
 	}
 
-	// Convert merged map back to NamedaysData slice
-	var result []domain.NamedaysData
-	var dates []string
-	for dateStr := range mergedMap {
-		dates = append(dates, dateStr)
+	for _, cmd := range commands {
+		if cmd.name == args[0] && cmd.name != "help" {
+			return cmd.run([]string{"-h"})
+		}
 	}
 
-	// Sort dates
-	sort.Strings(dates)
-
-	for _, dateStr := range dates {
-		// Parse date string back to DayMonth
-		month, _ := strconv.Atoi(dateStr[:2])
-		day, _ := strconv.Atoi(dateStr[2:])
-		date := domain.NewDayMonth(time.Date(time.Now().Year(), time.Month(month), day, 0, 0, 0, 0, time.UTC))
-
-		// Get unique names and sort them
-		var names []string
-		for name := range mergedMap[dateStr] {
-			names = append(names, name)
-		}
-		sort.Strings(names)
+	return fail("unknown command: %s", args[0])
+}
 
-		// Add to result
-		result = append(result, domain.NamedaysData{
-			Date:  date,
-			Names: names,
-		})
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: fetcher <command> [flags] [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.summary)
 	}
-
-	return result, nil
+	fmt.Fprintf(w, "\nRun \"fetcher <command> -h\" for the flags of a command.\n")
+	fmt.Fprintf(w, "\nExit codes: %d success, %d negative result (differences, failed checks, nothing found), %d error.\n",
+		exitOK, exitFindings, exitError)
 }
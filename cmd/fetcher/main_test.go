@@ -0,0 +1,128 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/domain/domaintest"
+	"github.com/kvloginov/namedays/internal/names"
+)
+
+// seedCache stores page as the cached response for url, laid out like the
+// HTTP cache of internal/fetch, so that -cache readonly fetches offline
+func seedCache(t *testing.T, dir, url, page string) {
+	t.Helper()
+	body, err := os.ReadFile(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(sum[:])
+	path := filepath.Join(dir, key[:2], key+".html")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+
+	var year domain.NamedaysDataList
+	for _, d := range domain.AllDays() {
+		year = append(year, domain.NamedaysData{Date: d, Names: []string{"Иван"}})
+	}
+	dataset := writeFile(t, dir, mergedFile, year)
+	other := writeFile(t, dir, "other.json", append(domain.NamedaysDataList{
+		{Date: domaintest.Day(t, time.January, 1), Names: []string{"Иван", "Ксения"}},
+	}, year[1:]...))
+	partial := writeFile(t, dir, "partial.json", year[:10])
+	writeFile(t, dir, names.DictionaryFile, names.Dictionary{Version: names.DictionaryVersion})
+
+	broken := filepath.Join(dir, "broken.json")
+	if err := os.WriteFile(broken, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.json")
+
+	rosterFile := filepath.Join(dir, "roster.yaml")
+	if err := os.WriteFile(rosterFile, []byte("version: 2\npeople:\n  - name: Иван\n    parents: [Ксения]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sourceDir, sourceDict := writeSources(t)
+
+	cacheDir := t.TempDir()
+	seedCache(t, cacheDir, "https://www.pravmir.ru/pravoslavnyj-kalendar-imenin/", "../../internal/fetch/testdata/pravmir.html")
+
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{nil, exitError},
+		{[]string{"nosuch"}, exitError},
+		{[]string{"-h"}, exitOK},
+		{[]string{"help"}, exitOK},
+		{[]string{"help", "query"}, exitOK},
+		{[]string{"help", "nosuch"}, exitError},
+		{[]string{"sources"}, exitOK},
+
+		{[]string{"fetch", "-cache", "readonly", "-cache-dir", cacheDir, "-retries", "0", "-out", out, "pravmir"}, exitOK},
+		{[]string{"fetch", "-cache", "readonly", "-cache-dir", t.TempDir(), "-retries", "0", "-out", out, "pravmir"}, exitError},
+		{[]string{"fetch", "-report", out, "calend"}, exitError},
+		{[]string{"fetch", "nosuch"}, exitError},
+
+		{[]string{"merge", "-data-dir", sourceDir, "-names-dict", sourceDict, "-out", out}, exitOK},
+		{[]string{"merge", "-data-dir", sourceDir, "-names-dict", missing, "-out", out}, exitError},
+
+		{[]string{"calendar", "-in", dataset, "-year", "2027", "-out", out}, exitOK},
+		{[]string{"calendar", "-in", broken, "-out", out}, exitError},
+
+		{[]string{"diff", "-names-dict", "none", "-out", out, dataset, dataset}, exitOK},
+		{[]string{"diff", "-names-dict", "none", "-out", out, dataset, other}, exitFindings},
+		{[]string{"diff", "-names-dict", "none", "-out", out, dataset, broken}, exitError},
+
+		{[]string{"validate", "-baseline", "", dataset}, exitOK},
+		{[]string{"validate", "-baseline", "", partial}, exitFindings},
+		{[]string{"validate", "-baseline", "", dataset, broken}, exitError},
+
+		{[]string{"query", "-data-dir", dir, "-out", out, "Иван"}, exitOK},
+		{[]string{"query", "-data-dir", dir, "-out", out, "Зосима"}, exitFindings},
+		{[]string{"query", "-data-dir", dir, "-in", broken, "-out", out, "Иван"}, exitError},
+
+		{[]string{"export", "-data-dir", dir, "-out", out}, exitOK},
+		{[]string{"export", "-data-dir", dir, "-format", "ics", "-year", "2027", "-out", out, "Иван"}, exitOK},
+		{[]string{"export", "-data-dir", dir, "-format", "nosuch", "-out", out}, exitError},
+
+		{[]string{"stats", "-out", out, dataset}, exitOK},
+		{[]string{"stats", "-out", out, broken}, exitError},
+
+		{[]string{"roster", "new", "-out", out + ".yaml", "Иван"}, exitOK},
+		{[]string{"roster", "validate", rosterFile}, exitOK},
+		{[]string{"roster", "validate", rosterFile, broken}, exitFindings},
+		{[]string{"roster", "nosuch"}, exitError},
+
+		{[]string{"leaderboard", "-data-dir", dir, "-date", "2026-12-31", "-out", out, rosterFile}, exitOK},
+		{[]string{"leaderboard", "-data-dir", dir, "-out", out, missing}, exitError},
+
+		{[]string{"serve", "-data-dir", dir, "-in", broken}, exitError},
+		{[]string{"serve", "-nosuch"}, exitError},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			if code := run(test.args); code != test.expected {
+				t.Errorf("Expected exit code %d, got %d", test.expected, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/fetch"
+	"github.com/kvloginov/namedays/internal/names"
+)
+
+func runMerge(args []string) int {
+	flags := newFlagSet("merge", "[flags]", "Merges the files of all registered sources in <data-dir> into one dataset.\nSpelling variants are grouped and every name records its sources.")
+	dataDir := flags.String("data-dir", defaultDataDir, "Directory with namedays files")
+	out := flags.String("out", "", "Output file, \"-\" for stdout (default <data-dir>/merged_namedays.json)")
+	minSources := flags.Int("min-sources", 1, "Keep only names reported by at least this many sources")
+	namesDict := flags.String("names-dict", "", namesDictUsage)
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	if *out == "" {
+		*out = filepath.Join(*dataDir, mergedFile)
+	}
+
+	namedays, err := mergeNamedaysFiles(*dataDir, dictionaryPath(*namesDict, *dataDir), *minSources)
+	if err != nil {
+		return fail("error merging namedays: %v", err)
+	}
+
+	if err := writeJSON(*out, namedays); err != nil {
+		return fail("%v", err)
+	}
+
+	log.Printf("Successfully merged namedays and saved to %s", *out)
+	return exitOK
+}
+
+// mergeNamedaysFiles merges all source files into one list. Spelling
+// variants of a name are grouped under its canonical form, and every name
+// records which sources reported it. Names reported by fewer than
+// minSources sources are dropped. A name gets the gender a source gave it
+// on the date, else the one from the dictionary, from a source on another
+// date or guessed from its ending. The saints of all spellings are kept.
+func mergeNamedaysFiles(dataDir, dictPath string, minSources int) ([]domain.NamedaysData, error) {
+	normalizer, err := loadNormalizer(dictPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map to store merged namedays data by date: spelling -> sources
+	mergedMap := make(map[domain.DayMonth]map[string][]string)
+	// Genders the sources gave, by date and spelling
+	genders := make(map[domain.DayMonth]map[string]domain.Gender)
+	// Saints the sources gave, by date and spelling
+	saints := make(map[domain.DayMonth]map[string][]domain.Saint)
+
+	// Read the output file of every registered source
+	for _, src := range fetch.Sources() {
+		source := src.Name
+		file := filepath.Join(dataDir, src.OutputFile)
+		if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
+			log.Printf("skipping %s: %s doesn't exist", source, file)
+			continue
+		}
+
+		namedaysList, err := loadNamedays(file)
+		if err != nil {
+			return nil, err
+		}
+
+		// Merge data
+		for _, nameday := range namedaysList {
+			date := nameday.Date
+
+			// Initialize map for this date if not exists
+			if _, ok := mergedMap[date]; !ok {
+				mergedMap[date] = make(map[string][]string)
+			}
+
+			// Add names for this date, a source may list a name twice
+			for _, name := range nameday.Names {
+				if !slices.Contains(mergedMap[date][name], source) {
+					mergedMap[date][name] = append(mergedMap[date][name], source)
+				}
+
+				if g := nameday.GenderOf(name); g != domain.GenderUnknown {
+					if genders[date] == nil {
+						genders[date] = make(map[string]domain.Gender)
+					}
+					if _, ok := genders[date][name]; !ok {
+						genders[date][name] = g
+					}
+					normalizer.LearnGender(name, g)
+				}
+
+				if given := nameday.SaintsOf(name); len(given) > 0 {
+					if saints[date] == nil {
+						saints[date] = make(map[string][]domain.Saint)
+					}
+					saints[date][name] = append(saints[date][name], given...)
+				}
+			}
+			normalizer.Learn(nameday.Names)
+		}
+	}
+
+	// Convert merged map back to NamedaysData slice
+	var result []domain.NamedaysData
+	var dates []domain.DayMonth
+	for date := range mergedMap {
+		dates = append(dates, date)
+	}
+
+	// Sort dates
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	for _, date := range dates {
+		spellings := mergedMap[date]
+		counts := make(map[string]int, len(spellings))
+		for spelling, sources := range spellings {
+			counts[spelling] = len(sources)
+		}
+
+		// Group spelling variants, clusters come sorted by canonical name
+		nameday := domain.NamedaysData{
+			Date:    date,
+			Sources: make(map[string][]domain.Attribution),
+		}
+		for _, cluster := range normalizer.Group(counts) {
+			var attributions []domain.Attribution
+			for _, spelling := range append([]string{cluster.Canonical}, cluster.Aliases...) {
+				for _, source := range spellings[spelling] {
+					attributions = append(attributions, domain.Attribution{Source: source, Spelling: spelling})
+				}
+			}
+			sort.Slice(attributions, func(i, j int) bool {
+				if attributions[i].Source != attributions[j].Source {
+					return attributions[i].Source < attributions[j].Source
+				}
+				return attributions[i].Spelling < attributions[j].Spelling
+			})
+			nameday.Sources[cluster.Canonical] = attributions
+
+			if nameday.SourceCount(cluster.Canonical) < minSources {
+				delete(nameday.Sources, cluster.Canonical)
+				continue
+			}
+
+			nameday.Names = append(nameday.Names, cluster.Canonical)
+			nameday.SetGender(cluster.Canonical, clusterGender(normalizer, cluster, genders[date]))
+			for _, spelling := range append([]string{cluster.Canonical}, cluster.Aliases...) {
+				for _, saint := range saints[date][spelling] {
+					nameday.AddSaint(cluster.Canonical, saint)
+				}
+			}
+			if len(cluster.Aliases) > 0 {
+				if nameday.Aliases == nil {
+					nameday.Aliases = make(map[string][]string)
+				}
+				nameday.Aliases[cluster.Canonical] = cluster.Aliases
+			}
+		}
+
+		// Skip dates where no name reached the quorum
+		if len(nameday.Names) == 0 {
+			continue
+		}
+
+		// Add to result
+		result = append(result, nameday)
+	}
+
+	return result, nil
+}
+
+// clusterGender returns the gender a source gave one of the spellings of
+// a cluster on its date, else the one the normalizer knows
+func clusterGender(normalizer *names.Normalizer, cluster names.Cluster, given map[string]domain.Gender) domain.Gender {
+	for _, spelling := range append([]string{cluster.Canonical}, cluster.Aliases...) {
+		if g, ok := given[spelling]; ok {
+			return g
+		}
+	}
+	return normalizer.Gender(cluster.Canonical)
+}
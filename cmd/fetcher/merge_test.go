@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/domain/domaintest"
+	"github.com/kvloginov/namedays/internal/names"
+)
+
+// writeFile saves v as JSON to name in dir and returns its path
+func writeFile(t *testing.T, dir, name string, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeSources saves the files of three sources that agree on Иван (once
+// spelled Иоанн), two of them on Илья and only one on Мария
+func writeSources(t *testing.T) (dataDir, dictPath string) {
+	t.Helper()
+	jan1, jan2 := domaintest.Day(t, time.January, 1), domaintest.Day(t, time.January, 2)
+	saint := domain.Saint{Title: "прор. Иоанн Креститель", Type: domain.Prophet}
+
+	dataDir = t.TempDir()
+	writeFile(t, dataDir, "calend_namedays.json", domain.NamedaysDataList{
+		{Date: jan1, Names: []string{"Иван", "Илья"}, Meta: map[string]domain.NameMeta{"Илья": {Gender: domain.Male}}},
+		{Date: jan2, Names: []string{"Мария"}},
+	})
+	writeFile(t, dataDir, "krestilnoe_namedays.json", domain.NamedaysDataList{
+		{Date: jan1, Names: []string{"Иоанн", "Илья"}, Meta: map[string]domain.NameMeta{"Иоанн": {Gender: domain.Male}}},
+	})
+	writeFile(t, dataDir, "pravmir_namedays.json", domain.NamedaysDataList{
+		{Date: jan1, Names: []string{"Иван"}, Meta: map[string]domain.NameMeta{"Иван": {Saints: []domain.Saint{saint}}}},
+	})
+
+	dict := names.Dictionary{Version: names.DictionaryVersion, Groups: []names.Group{{Canonical: "Иван", Variants: []string{"Иоанн"}}}}
+	return dataDir, writeFile(t, t.TempDir(), names.DictionaryFile, dict)
+}
+
+func TestMergeNamedaysFiles(t *testing.T) {
+	dataDir, dictPath := writeSources(t)
+	jan1, jan2 := domaintest.Day(t, time.January, 1), domaintest.Day(t, time.January, 2)
+
+	ivan := domain.NameMeta{
+		Gender: domain.Male,
+		Saints: []domain.Saint{{Title: "прор. Иоанн Креститель", Type: domain.Prophet}},
+	}
+	ivanSources := []domain.Attribution{
+		{Source: "calend", Spelling: "Иван"},
+		{Source: "krestilnoe", Spelling: "Иоанн"},
+		{Source: "pravmir", Spelling: "Иван"},
+	}
+	ilyaSources := []domain.Attribution{{Source: "calend", Spelling: "Илья"}, {Source: "krestilnoe", Spelling: "Илья"}}
+
+	tests := []struct {
+		minSources int
+		expected   domain.NamedaysDataList
+	}{
+		{1, domain.NamedaysDataList{
+			{
+				Date:    jan1,
+				Names:   []string{"Иван", "Илья"},
+				Aliases: map[string][]string{"Иван": {"Иоанн"}},
+				Sources: map[string][]domain.Attribution{"Иван": ivanSources, "Илья": ilyaSources},
+				Meta:    map[string]domain.NameMeta{"Иван": ivan, "Илья": {Gender: domain.Male}},
+			},
+			{
+				Date:    jan2,
+				Names:   []string{"Мария"},
+				Sources: map[string][]domain.Attribution{"Мария": {{Source: "calend", Spelling: "Мария"}}},
+				Meta:    map[string]domain.NameMeta{"Мария": {Gender: domain.Female}},
+			},
+		}},
+		{2, domain.NamedaysDataList{
+			{
+				Date:    jan1,
+				Names:   []string{"Иван", "Илья"},
+				Aliases: map[string][]string{"Иван": {"Иоанн"}},
+				Sources: map[string][]domain.Attribution{"Иван": ivanSources, "Илья": ilyaSources},
+				Meta:    map[string]domain.NameMeta{"Иван": ivan, "Илья": {Gender: domain.Male}},
+			},
+		}},
+		{3, domain.NamedaysDataList{
+			{
+				Date:    jan1,
+				Names:   []string{"Иван"},
+				Aliases: map[string][]string{"Иван": {"Иоанн"}},
+				Sources: map[string][]domain.Attribution{"Иван": ivanSources},
+				Meta:    map[string]domain.NameMeta{"Иван": ivan},
+			},
+		}},
+	}
+
+	for _, test := range tests {
+		namedays, err := mergeNamedaysFiles(dataDir, dictPath, test.minSources)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(domain.NamedaysDataList(namedays), test.expected) {
+			t.Errorf("Expected %+v with min sources %d, got %+v", test.expected, test.minSources, namedays)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/query"
+)
+
+// nextResult is the JSON answer of query -next
+type nextResult struct {
+	query.Match
+	On string `json:"on"`
+}
+
+func runQuery(args []string) int {
+	flags := newFlagSet("query", "[flags] <name|MMDD>", "Shows the dates of a name's namedays, or the names on a date given as MMDD.\nNames match regardless of case, ё and the spelling variants of the names\ndictionary, and may be typed in Latin script in any common transliteration,\nlike Ksenia or Kseniya. Short forms like Саша or Женя match the namedays of\nall of their full names. Each result lists the sources that reported it.")
+	dataDir := flags.String("data-dir", defaultDataDir, "Directory with namedays files")
+	in := flags.String("in", "", "Input dataset (default <data-dir>/merged_namedays.json)")
+	namesDict := flags.String("names-dict", "", namesDictUsage)
+	next := flags.String("next", "", "Show only the next nameday of the name on or after this date, YYYY-MM-DD or \"today\"")
+	out := flags.String("out", "-", "Output file, \"-\" for stdout")
+	format := flags.String("format", "text", "Output format: text or json")
+	genderFlag := flags.String("gender", "", genderUsage)
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitError
+	}
+	if *format != "text" && *format != "json" {
+		return fail("unknown format: %s (expected text or json)", *format)
+	}
+	gender, err := parseGenderFlag(*genderFlag)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if *in == "" {
+		*in = filepath.Join(*dataDir, mergedFile)
+	}
+
+	namedays, err := loadNamedays(*in)
+	if err != nil {
+		return fail("%v", err)
+	}
+	normalizer, err := loadNormalizer(dictionaryPath(*namesDict, *dataDir))
+	if err != nil {
+		return fail("%v", err)
+	}
+	index := query.New(namedays, normalizer)
+	if gender != domain.GenderUnknown {
+		index = index.OnlyGender(gender)
+	}
+
+	arg := flags.Arg(0)
+	if *next != "" {
+		from, err := parseQueryDate(*next)
+		if err != nil {
+			return fail("%v", err)
+		}
+		return queryNext(index, arg, from, *out, *format)
+	}
+
+	var result []query.Match
+	date, err := domain.ParseDayMonth(arg)
+	byDate := err == nil
+	if byDate {
+		result = index.ByDate(date)
+	} else {
+		result = index.ByName(arg)
+	}
+
+	err = writeOutput(*out, func(w io.Writer) error {
+		if *format == "json" {
+			return json.NewEncoder(w).Encode(result)
+		}
+		if byDate && len(result) > 0 {
+			if _, err := fmt.Fprintf(w, "%s:\n", date.Russian()); err != nil {
+				return err
+			}
+		}
+		for _, m := range result {
+			label := "  " + describeMatch(m)
+			if !byDate {
+				label = m.Date.Russian() + ": " + describeMatch(m)
+			}
+			if _, err := fmt.Fprintln(w, label); err != nil {
+				return err
+			}
+			if err := writeSaints(w, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	if len(result) == 0 {
+		return exitFindings
+	}
+	return exitOK
+}
+
+func queryNext(index *query.Index, name string, from time.Time, out, format string) int {
+	match, on, found := index.Next(name, from)
+	if !found {
+		return exitFindings
+	}
+
+	err := writeOutput(out, func(w io.Writer) error {
+		if format == "json" {
+			return json.NewEncoder(w).Encode(nextResult{Match: match, On: on.Format(time.DateOnly)})
+		}
+		if _, err := fmt.Fprintf(w, "%s %d: %s\n", domain.NewDayMonth(on).Russian(), on.Year(), describeMatch(match)); err != nil {
+			return err
+		}
+		return writeSaints(w, match)
+	})
+	if err != nil {
+		return fail("%v", err)
+	}
+	return exitOK
+}
+
+// describeMatch formats a match as "Иван (Иоанн) — calend, pravmir", or
+// "Саша → Александр — calend" when it was looked up by a short form
+func describeMatch(m query.Match) string {
+	label := m.Name
+	if m.Diminutive != "" {
+		label = m.Diminutive + " → " + m.Name
+	}
+	if len(m.Aliases) > 0 {
+		label += " (" + strings.Join(m.Aliases, ", ") + ")"
+	}
+	if sources := m.SourceNames(); len(sources) > 0 {
+		label += " — " + strings.Join(sources, ", ")
+	}
+	return label
+}
+
+// writeSaints lists the saints of a match below it, one per line with the
+// link to their page
+func writeSaints(w io.Writer, m query.Match) error {
+	for _, saint := range m.Saints {
+		if _, err := fmt.Fprintf(w, "    память: %s\n", strings.TrimSpace(saint.String()+" "+saint.URL)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseQueryDate parses a YYYY-MM-DD date or "today" in local time
+func parseQueryDate(value string) (time.Time, error) {
+	if value == "today" {
+		return time.Now(), nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD or today)", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kvloginov/namedays/internal/roster"
+	"github.com/kvloginov/namedays/internal/scoring"
+)
+
+// rosterCommands are the subcommands of roster
+var rosterCommands = []command{
+	{"new", "Create a roster of people without relatives", runRosterNew},
+	{"validate", "Check roster files and links", runRosterValidate},
+	{"migrate", "Upgrade a roster or link to the current version", runRosterMigrate},
+	{"encode", "Turn a roster into the names parameter of a shareable link", runRosterEncode},
+	{"decode", "Turn a shared link back into a roster file", runRosterDecode},
+}
+
+func runRoster(args []string) int {
+	if len(args) > 0 {
+		for _, cmd := range rosterCommands {
+			if cmd.name == args[0] {
+				return cmd.run(args[1:])
+			}
+		}
+	}
+
+	out := os.Stderr
+	code := exitError
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		out, code = os.Stdout, exitOK
+	} else if len(args) > 0 {
+		fmt.Fprintf(out, "unknown roster command: %s\n\n", args[0])
+	}
+
+	fmt.Fprintf(out, "Usage: fetcher roster <command> [flags] [arguments]\n\n"+
+		"Rosters list the people of a namedays competition with their parents and\n"+
+		"grandparents, as YAML or JSON files or as the names parameter of a link\n"+
+		"to the web page. A person's gender is optional and picks the full name of\n"+
+		"a short form like Саша. Commands that read a roster take a file or a link.\n\nCommands:\n")
+	for _, cmd := range rosterCommands {
+		fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	return code
+}
+
+func runRosterNew(args []string) int {
+	flags := newFlagSet("roster new", "[flags] <name...>", "Writes a roster of the given people, to fill in their relatives later.")
+	out := flags.String("out", "-", "Output file, \"-\" for stdout")
+	format := flags.String("format", "", "Output format: yaml or json (default by the -out extension, yaml for stdout)")
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	var people []scoring.Person
+	for _, name := range flags.Args() {
+		people = append(people, scoring.Person{Name: name})
+	}
+	return writeRoster(roster.New(people...), *out, *format)
+}
+
+func runRosterValidate(args []string) int {
+	flags := newFlagSet("roster validate", "<file|link...>", "Checks that rosters parse and every person and relative has a name.")
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	code := exitOK
+	for _, source := range flags.Args() {
+		r, err := readRoster(source)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", shorten(source), err)
+			code = exitFindings
+			continue
+		}
+
+		note := ""
+		switch {
+		case r.Version == 0:
+			note = ", unversioned, run roster migrate to upgrade it"
+		case r.Version != roster.Version:
+			note = fmt.Sprintf(", version %d, run roster migrate to upgrade it", r.Version)
+		}
+		fmt.Printf("ok   %s: %d people%s\n", shorten(source), len(r.People), note)
+	}
+	return code
+}
+
+func runRosterMigrate(args []string) int {
+	flags := newFlagSet("roster migrate", "[flags] <file|link>", "Upgrades a roster file or a shared link to the current roster version.")
+	out := flags.String("out", "-", "Output file, \"-\" for stdout; may be the input file")
+	format := flags.String("format", "", "Output format: yaml or json (default by the -out extension, yaml for stdout)")
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitError
+	}
+
+	r, err := readRoster(flags.Arg(0))
+	if err != nil {
+		return fail("%v", err)
+	}
+	r.Migrate()
+	return writeRoster(r, *out, *format)
+}
+
+func runRosterEncode(args []string) int {
+	flags := newFlagSet("roster encode", "[flags] <file>", "Prints the names parameter that the web page reads the roster from, or the\nwhole link with -base.")
+	base := flags.String("base", "", "URL of the web page to build the link from")
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitError
+	}
+
+	r, err := readRoster(flags.Arg(0))
+	if err != nil {
+		return fail("%v", err)
+	}
+	r.Migrate()
+
+	var encoded string
+	if *base != "" {
+		encoded, err = r.ShareURL(*base)
+	} else {
+		encoded, err = r.EncodeURL()
+	}
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	fmt.Println(encoded)
+	return exitOK
+}
+
+func runRosterDecode(args []string) int {
+	flags := newFlagSet("roster decode", "[flags] <link|names>", "Writes the roster of a shared link, or of the value of its names parameter.")
+	out := flags.String("out", "-", "Output file, \"-\" for stdout")
+	format := flags.String("format", "", "Output format: yaml or json (default by the -out extension, yaml for stdout)")
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitError
+	}
+
+	r, err := roster.DecodeURL(flags.Arg(0))
+	if err != nil {
+		return fail("%v", err)
+	}
+	return writeRoster(r, *out, *format)
+}
+
+// readRoster reads a roster from a file or, if there is no such file, from
+// a shared link or the value of its names parameter
+func readRoster(source string) (*roster.Roster, error) {
+	if _, err := os.Stat(source); err == nil {
+		return roster.Load(source)
+	}
+
+	r, err := roster.DecodeURL(source)
+	if err != nil {
+		return nil, fmt.Errorf("not a roster file or link: %v", err)
+	}
+	return r, nil
+}
+
+// writeRoster writes r to path in format, or in the format of the path's
+// extension if format is empty
+func writeRoster(r *roster.Roster, path, format string) int {
+	var outputFormat roster.Format
+	var err error
+	switch {
+	case format != "":
+		outputFormat, err = roster.ParseFormat(format)
+	case path == "-":
+		outputFormat = roster.FormatYAML
+	default:
+		outputFormat, err = roster.FormatOf(path)
+	}
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	err = writeOutput(path, func(w io.Writer) error {
+		return r.Write(w, outputFormat)
+	})
+	if err != nil {
+		return fail("%v", err)
+	}
+	return exitOK
+}
+
+// shorten cuts long links for messages
+func shorten(s string) string {
+	if len(s) <= 60 {
+		return s
+	}
+	return strings.TrimSpace(s[:57]) + "..."
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"path/filepath"
+	"time"
+	// Embedded so that ?tz= works on hosts without a zoneinfo database
+	_ "time/tzdata"
+
+	"github.com/kvloginov/namedays/internal/server"
+)
+
+func runServe(args []string) int {
+	flags := newFlagSet("serve", "[flags]", "Serves the namedays dataset as a JSON API:\n\n"+
+		"  GET /v1/days/{MMDD}          names celebrated on a date\n"+
+		"  GET /v1/today?tz=            names celebrated today in a time zone\n"+
+		"  GET /v1/names/{name}         all namedays of a name, or of the full names of a\n"+
+		"                               short form like Саша\n"+
+		"  GET /v1/names/{name}/next    next nameday, with ?from=YYYY-MM-DD&tz=\n"+
+		"  GET /v1/search?q=            names containing q, with ?limit=\n"+
+		"  GET /v1/calendar.ics?name=   iCalendar feed of the namedays of names, with ?remind=15h\n"+
+		"  GET /v1/leaderboard          ranking of the -roster or of ?names= from a shared link,\n"+
+		"                               with ?date=&tz=&format=json|csv|table\n"+
+		"  POST /v1/leaderboard         ranking of the roster in the body, JSON or YAML\n\n"+
+		"Days, today, names, next, search and the calendar take ?gender=male|female.\n"+
+		"Names and searches may be typed in Latin script, like Ksenia or Aleksandr.\n"+
+		"The data files and the roster are reloaded when they change.")
+	addr := flags.String("addr", "localhost:8080", "Address to listen on")
+	dataDir := flags.String("data-dir", defaultDataDir, "Directory with namedays files")
+	in := flags.String("in", "", "Dataset to serve (default <data-dir>/merged_namedays.json)")
+	namesDict := flags.String("names-dict", "", namesDictUsage)
+	rosterFile := flags.String("roster", "", "Roster file (YAML or JSON) ranked by /v1/leaderboard")
+	tz := flags.String("tz", "Europe/Moscow", "Default time zone of /v1/today and /v1/names/{name}/next")
+	allowOrigin := flags.String("cors-origin", "*", "Access-Control-Allow-Origin of responses, \"\" to disable CORS")
+	reload := flags.Duration("reload", 2*time.Second, "How often to check the data files for changes, 0 disables reloading")
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	if *in == "" {
+		*in = filepath.Join(*dataDir, mergedFile)
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return fail("unknown time zone: %s", *tz)
+	}
+
+	srv, err := server.New(*in,
+		server.WithDictionary(dictionaryPath(*namesDict, *dataDir)),
+		server.WithRoster(*rosterFile),
+		server.WithAllowOrigin(*allowOrigin),
+		server.WithLocation(loc),
+	)
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	ctx, cancel := signalContext(0)
+	defer cancel()
+
+	if *reload > 0 {
+		go srv.Watch(ctx, *reload)
+	}
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Serving %s on http://%s", *in, *addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fail("%v", err)
+	}
+	return exitOK
+}
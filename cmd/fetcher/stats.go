@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/names"
+)
+
+// datasetStats summarizes one namedays file
+type datasetStats struct {
+	File         string  `json:"file"`
+	Dates        int     `json:"dates"`
+	MissingDates int     `json:"missing_dates"`
+	Names        int     `json:"names"`
+	UniqueNames  int     `json:"unique_names"`
+	NamesPerDate float64 `json:"names_per_date"`
+	MaxNames     int     `json:"max_names"`
+	MaxNamesDate string  `json:"max_names_date,omitempty"`
+}
+
+func runStats(args []string) int {
+	flags := newFlagSet("stats", "[flags] [file...]", "Shows statistics about namedays files.\nUses the files of all registered sources and the merged file if none are given.")
+	dataDir := flags.String("data-dir", defaultDataDir, "Directory with namedays files")
+	out := flags.String("out", "-", "Output file, \"-\" for stdout")
+	format := flags.String("format", "text", "Output format: text or json")
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	if *format != "text" && *format != "json" {
+		return fail("unknown format: %s (expected text or json)", *format)
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = datasetFiles(*dataDir)
+	}
+
+	var stats []datasetStats
+	for _, file := range files {
+		namedays, err := loadNamedays(file)
+		if err != nil {
+			return fail("%v", err)
+		}
+		stats = append(stats, computeStats(file, namedays))
+	}
+
+	err := writeOutput(*out, func(w io.Writer) error {
+		if *format == "json" {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(stats)
+		}
+
+		for _, s := range stats {
+			_, err := fmt.Fprintf(w, "%s\n  dates: %d (%d missing)\n  names: %d, unique: %d, per date: %.1f\n  most names: %d on %s\n",
+				s.File, s.Dates, s.MissingDates, s.Names, s.UniqueNames, s.NamesPerDate, s.MaxNames, s.MaxNamesDate)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	return exitOK
+}
+
+func computeStats(file string, namedays domain.NamedaysDataList) datasetStats {
+	s := datasetStats{File: file}
+
+	dates := make(map[domain.DayMonth]bool)
+	unique := make(map[string]bool)
+	for _, n := range namedays {
+		dates[n.Date] = true
+		s.Names += len(n.Names)
+		for _, name := range n.Names {
+			unique[names.Fold(name)] = true
+		}
+		if len(n.Names) > s.MaxNames {
+			s.MaxNames = len(n.Names)
+			s.MaxNamesDate = n.Date.String()
+		}
+	}
+
+	s.Dates = len(dates)
+	s.MissingDates = domain.DaysInYear - len(dates)
+	s.UniqueNames = len(unique)
+	if s.Dates > 0 {
+		s.NamesPerDate = float64(s.Names) / float64(s.Dates)
+	}
+
+	return s
+}
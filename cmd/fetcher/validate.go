@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/validate"
+)
+
+func runValidate(args []string) int {
+	flags := newFlagSet("validate", "[flags] [file...]", "Checks namedays files for completeness and sanity: missing and duplicate\ndates, empty name lists, leftovers of the page text and names that lost\nmany entries since the previous version of the file. Validates the files\nof all registered sources and the merged file if none are given.\n\nExits with 1 if any file has errors, warnings only fail with -strict,\nand with 2 if a file can't be read.")
+	dataDir := flags.String("data-dir", defaultDataDir, "Directory with namedays files")
+	baseline := flags.String("baseline", "HEAD", "Git revision to compare name counts with, \"\" to skip the comparison")
+	maxLength := flags.Int("max-length", validate.DefaultOptions().MaxNameLength, "Longest plausible name, in letters")
+	maxDrop := flags.Float64("max-drop", validate.DefaultOptions().MaxDrop, "Largest tolerated share of names a date may lose, from 0 to 1")
+	strict := flags.Bool("strict", false, "Treat warnings as errors")
+	if code, stop := parseFlags(flags, args); stop {
+		return code
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = datasetFiles(*dataDir)
+	}
+
+	code := exitOK
+	for _, file := range files {
+		namedays, err := loadNamedays(file)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", file, err)
+			code = exitError
+			continue
+		}
+
+		opts := validate.DefaultOptions()
+		opts.MaxNameLength = *maxLength
+		opts.MaxDrop = *maxDrop
+		if *baseline != "" {
+			previous, err := loadCommitted(*baseline, file)
+			if err != nil {
+				fmt.Printf("     %s: not compared with %s: %v\n", file, *baseline, err)
+			}
+			opts.Previous = previous
+		}
+
+		issues := validate.Check(namedays, opts)
+		failed := validate.HasErrors(issues) || (*strict && len(issues) > 0)
+
+		status := "ok  "
+		if failed {
+			status = "FAIL"
+			code = max(code, exitFindings)
+		}
+		fmt.Printf("%s %s: %d dates, %d issues\n", status, file, len(namedays), len(issues))
+		for _, issue := range issues {
+			fmt.Printf("     %s\n", issue)
+		}
+	}
+
+	return code
+}
+
+// loadCommitted reads the version of a namedays file at a git revision
+func loadCommitted(revision, path string) (domain.NamedaysDataList, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// "./" makes git resolve the path relative to the file's directory
+	cmd := exec.Command("git", "show", revision+":./"+filepath.Base(abs))
+	cmd.Dir = filepath.Dir(abs)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return nil, fmt.Errorf("%s", msg)
+		}
+		return nil, err
+	}
+
+	var namedays domain.NamedaysDataList
+	if err := json.Unmarshal(out, &namedays); err != nil {
+		return nil, fmt.Errorf("error parsing %s:%s: %w", revision, path, err)
+	}
+	return namedays, nil
+}
@@ -0,0 +1,89 @@
+package diff
+
+import (
+	"sort"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/names"
+)
+
+// Report is the difference between two namedays datasets, A and B
+type Report struct {
+	// MissingInA are dates B has names for but A doesn't
+	MissingInA []domain.DayMonth `json:"missing_in_a"`
+	// MissingInB are dates A has names for but B doesn't
+	MissingInB []domain.DayMonth `json:"missing_in_b"`
+	// Days lists the dates present in both datasets whose names differ
+	Days []DayDiff `json:"days"`
+}
+
+// DayDiff is the difference in names on one date
+type DayDiff struct {
+	Date    domain.DayMonth `json:"date"`
+	OnlyInA []string        `json:"only_in_a"`
+	OnlyInB []string        `json:"only_in_b"`
+}
+
+// Empty reports whether the datasets have the same dates and names
+func (r Report) Empty() bool {
+	return len(r.MissingInA) == 0 && len(r.MissingInB) == 0 && len(r.Days) == 0
+}
+
+// Compare returns the differences between a and b. Names are compared
+// case- and ё-insensitively and, if normalizer is not nil, variants like
+// Иоанн/Иван count as the same name. A date listed several times counts
+// once with all its names.
+func Compare(a, b domain.NamedaysDataList, normalizer *names.Normalizer) Report {
+	namesA := byDate(a, normalizer)
+	namesB := byDate(b, normalizer)
+
+	report := Report{}
+	for _, date := range domain.AllDays() {
+		dayA, inA := namesA[date]
+		dayB, inB := namesB[date]
+
+		switch {
+		case inA && !inB:
+			report.MissingInB = append(report.MissingInB, date)
+		case !inA && inB:
+			report.MissingInA = append(report.MissingInA, date)
+		case inA && inB:
+			onlyA := subtract(dayA, dayB)
+			onlyB := subtract(dayB, dayA)
+			if len(onlyA) > 0 || len(onlyB) > 0 {
+				report.Days = append(report.Days, DayDiff{Date: date, OnlyInA: onlyA, OnlyInB: onlyB})
+			}
+		}
+	}
+
+	return report
+}
+
+// byDate maps each date to its names keyed by their comparison key
+func byDate(list domain.NamedaysDataList, normalizer *names.Normalizer) map[domain.DayMonth]map[string]string {
+	result := make(map[domain.DayMonth]map[string]string)
+	for _, n := range list {
+		if len(n.Names) == 0 {
+			continue
+		}
+		if result[n.Date] == nil {
+			result[n.Date] = make(map[string]string)
+		}
+		for _, name := range n.Names {
+			result[n.Date][normalizer.Key(name)] = name
+		}
+	}
+	return result
+}
+
+// subtract returns the names in a that aren't in b, sorted
+func subtract(a, b map[string]string) []string {
+	var result []string
+	for key, name := range a {
+		if _, ok := b[key]; !ok {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
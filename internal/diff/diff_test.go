@@ -0,0 +1,45 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/domain/domaintest"
+	"github.com/kvloginov/namedays/internal/names"
+)
+
+func TestCompare(t *testing.T) {
+	a := domain.NamedaysDataList{
+		{Date: domaintest.Day(t, time.January, 1), Names: []string{"Илья", "Пётр", "Иоанн"}},
+		{Date: domaintest.Day(t, time.January, 2), Names: []string{"Даниил"}},
+	}
+	b := domain.NamedaysDataList{
+		{Date: domaintest.Day(t, time.January, 1), Names: []string{"Петр", "Иван", "Трифон"}},
+		{Date: domaintest.Day(t, time.January, 3), Names: []string{"Михаил"}},
+	}
+
+	dict := &names.Dictionary{Version: names.DictionaryVersion, Groups: []names.Group{{Canonical: "Иван", Variants: []string{"Иоанн"}}}}
+	report := Compare(a, b, names.NewNormalizer(dict))
+
+	expected := Report{
+		MissingInA: []domain.DayMonth{domaintest.Day(t, time.January, 3)},
+		MissingInB: []domain.DayMonth{domaintest.Day(t, time.January, 2)},
+		Days: []DayDiff{
+			{Date: domaintest.Day(t, time.January, 1), OnlyInA: []string{"Илья"}, OnlyInB: []string{"Трифон"}},
+		},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("Expected:\n%+v\nGot:\n%+v", expected, report)
+	}
+
+	var out strings.Builder
+	if err := report.Write(&out, FormatMarkdown, "a", "b"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "| 1 января | Илья | Трифон |") {
+		t.Errorf("Unexpected markdown:\n%s", out.String())
+	}
+}
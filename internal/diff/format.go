@@ -0,0 +1,114 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+// Format is an output format for a Report
+type Format string
+
+const (
+	FormatText     Format = "text"
+	FormatJSON     Format = "json"
+	FormatMarkdown Format = "markdown"
+)
+
+// ParseFormat parses a format name as given on the command line
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatText, FormatJSON, FormatMarkdown:
+		return f, nil
+	case "md":
+		return FormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("unknown format: %s (expected text, json or markdown)", s)
+	}
+}
+
+// Write renders the report in the given format. nameA and nameB label the
+// two datasets, e.g. with their file names.
+func (r Report) Write(w io.Writer, format Format, nameA, nameB string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatMarkdown:
+		return r.writeMarkdown(w, nameA, nameB)
+	default:
+		return r.writeText(w, nameA, nameB)
+	}
+}
+
+func (r Report) writeText(w io.Writer, nameA, nameB string) error {
+	var b strings.Builder
+
+	if r.Empty() {
+		fmt.Fprintf(&b, "%s and %s have the same namedays\n", nameA, nameB)
+	}
+	if len(r.MissingInA) > 0 {
+		fmt.Fprintf(&b, "Dates missing in %s: %s\n", nameA, joinDates(r.MissingInA))
+	}
+	if len(r.MissingInB) > 0 {
+		fmt.Fprintf(&b, "Dates missing in %s: %s\n", nameB, joinDates(r.MissingInB))
+	}
+
+	for _, day := range r.Days {
+		fmt.Fprintf(&b, "\n%s (%s)\n", day.Date, day.Date.Russian())
+		if len(day.OnlyInA) > 0 {
+			fmt.Fprintf(&b, "  - only in %s: %s\n", nameA, strings.Join(day.OnlyInA, ", "))
+		}
+		if len(day.OnlyInB) > 0 {
+			fmt.Fprintf(&b, "  + only in %s: %s\n", nameB, strings.Join(day.OnlyInB, ", "))
+		}
+	}
+
+	if !r.Empty() {
+		fmt.Fprintf(&b, "\n%d dates differ, %d missing in %s, %d missing in %s\n",
+			len(r.Days), len(r.MissingInA), nameA, len(r.MissingInB), nameB)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (r Report) writeMarkdown(w io.Writer, nameA, nameB string) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "| Date | Only in %s | Only in %s |\n", nameA, nameB)
+	b.WriteString("| --- | --- | --- |\n")
+
+	// One row per date in calendar order, missing dates included
+	rows := make(map[domain.DayMonth]string)
+	for _, date := range r.MissingInA {
+		rows[date] = "*date missing* | "
+	}
+	for _, date := range r.MissingInB {
+		rows[date] = " | *date missing*"
+	}
+	for _, day := range r.Days {
+		rows[day.Date] = strings.Join(day.OnlyInA, ", ") + " | " + strings.Join(day.OnlyInB, ", ")
+	}
+
+	for _, date := range domain.AllDays() {
+		if row, ok := rows[date]; ok {
+			fmt.Fprintf(&b, "| %s | %s |\n", date.Russian(), strings.TrimSpace(row))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func joinDates(dates []domain.DayMonth) string {
+	parts := make([]string, len(dates))
+	for i, date := range dates {
+		parts[i] = date.String()
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,234 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DaysInYear is the number of distinct DayMonth values, including Feb 29
+const DaysInYear = 366
+
+// DayMonth is a calendar day without a year, e.g. January 14. Feb 29 is
+// always a valid DayMonth, even though it only exists in leap years.
+// The zero value is not a valid date.
+type DayMonth struct {
+	month time.Month
+	day   int
+}
+
+// NewDayMonth takes the month and day of ts, ignoring the year and time
+func NewDayMonth(ts time.Time) DayMonth {
+	return DayMonth{month: ts.Month(), day: ts.Day()}
+}
+
+// MakeDayMonth returns the given day of month or an error if there is no
+// such date, e.g. February 31
+func MakeDayMonth(month time.Month, day int) (DayMonth, error) {
+	d := DayMonth{month: month, day: day}
+	if !d.IsValid() {
+		return DayMonth{}, fmt.Errorf("invalid date: month %d, day %d", month, day)
+	}
+	return d, nil
+}
+
+// ParseDayMonth parses a date in MMDD format, e.g. "0114"
+func ParseDayMonth(s string) (DayMonth, error) {
+	if len(s) != 4 {
+		return DayMonth{}, fmt.Errorf("invalid date format: %s, expected MMDD", s)
+	}
+
+	month, err := strconv.Atoi(s[:2])
+	if err != nil {
+		return DayMonth{}, fmt.Errorf("invalid month: %s", s[:2])
+	}
+
+	day, err := strconv.Atoi(s[2:])
+	if err != nil {
+		return DayMonth{}, fmt.Errorf("invalid day: %s", s[2:])
+	}
+
+	d, err := MakeDayMonth(time.Month(month), day)
+	if err != nil {
+		return DayMonth{}, fmt.Errorf("invalid date %s: %w", s, err)
+	}
+
+	return d, nil
+}
+
+// DaysIn returns the number of days in month, counting Feb 29
+func DaysIn(month time.Month) int {
+	if month == time.February {
+		return 29
+	}
+	// Day 0 of the next month is the last day of this one
+	return time.Date(2001, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// AllDays returns every day of the year in order, from January 1 to
+// December 31, including Feb 29
+func AllDays() []DayMonth {
+	days := make([]DayMonth, 0, DaysInYear)
+	for d := (DayMonth{month: time.January, day: 1}); ; d = d.Next() {
+		days = append(days, d)
+		if d.month == time.December && d.day == 31 {
+			return days
+		}
+	}
+}
+
+func (d DayMonth) Month() time.Month {
+	return d.month
+}
+
+func (d DayMonth) Day() int {
+	return d.day
+}
+
+// IsValid reports whether d is a real date in a leap year
+func (d DayMonth) IsValid() bool {
+	return d.month >= time.January && d.month <= time.December &&
+		d.day >= 1 && d.day <= DaysIn(d.month)
+}
+
+// ExistsIn reports whether d exists in year, i.e. it isn't Feb 29 of a
+// non-leap year
+func (d DayMonth) ExistsIn(year int) bool {
+	if d.month == time.February && d.day == 29 {
+		return isLeapYear(year)
+	}
+	return d.IsValid()
+}
+
+// In returns d as midnight of the given year in loc. It returns an error
+// for Feb 29 of a non-leap year instead of rolling over to March 1.
+func (d DayMonth) In(year int, loc *time.Location) (time.Time, error) {
+	if !d.ExistsIn(year) {
+		return time.Time{}, fmt.Errorf("%s doesn't exist in %d", d, year)
+	}
+	return time.Date(year, d.month, d.day, 0, 0, 0, 0, loc), nil
+}
+
+// Compare returns -1 if d is before o, 1 if it is after, and 0 if they are
+// the same day
+func (d DayMonth) Compare(o DayMonth) int {
+	switch {
+	case d.month < o.month || (d.month == o.month && d.day < o.day):
+		return -1
+	case d == o:
+		return 0
+	default:
+		return 1
+	}
+}
+
+func (d DayMonth) Before(o DayMonth) bool {
+	return d.Compare(o) < 0
+}
+
+func (d DayMonth) After(o DayMonth) bool {
+	return d.Compare(o) > 0
+}
+
+// Next returns the following day, wrapping from December 31 to January 1
+func (d DayMonth) Next() DayMonth {
+	if d.day < DaysIn(d.month) {
+		return DayMonth{month: d.month, day: d.day + 1}
+	}
+	if d.month == time.December {
+		return DayMonth{month: time.January, day: 1}
+	}
+	return DayMonth{month: d.month + 1, day: 1}
+}
+
+// Prev returns the preceding day, wrapping from January 1 to December 31
+func (d DayMonth) Prev() DayMonth {
+	if d.day > 1 {
+		return DayMonth{month: d.month, day: d.day - 1}
+	}
+	if d.month == time.January {
+		return DayMonth{month: time.December, day: 31}
+	}
+	return DayMonth{month: d.month - 1, day: DaysIn(d.month - 1)}
+}
+
+// genitiveMonths are Russian month names as used in dates ("14 января")
+var genitiveMonths = [...]string{
+	"января", "февраля", "марта", "апреля", "мая", "июня",
+	"июля", "августа", "сентября", "октября", "ноября", "декабря",
+}
+
+// Russian formats d the way Russian calendars do, e.g. "14 января"
+func (d DayMonth) Russian() string {
+	if !d.IsValid() {
+		return d.String()
+	}
+	return fmt.Sprintf("%d %s", d.day, genitiveMonths[d.month-1])
+}
+
+func (d DayMonth) String() string {
+	return fmt.Sprintf("%02d%02d", d.month, d.day)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface
+func (d DayMonth) MarshalText() ([]byte, error) {
+	if !d.IsValid() {
+		return nil, fmt.Errorf("invalid date: %s", d)
+	}
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface
+func (d *DayMonth) UnmarshalText(text []byte) error {
+	parsed, err := ParseDayMonth(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface as two
+// bytes: month and day
+func (d DayMonth) MarshalBinary() ([]byte, error) {
+	if !d.IsValid() {
+		return nil, fmt.Errorf("invalid date: %s", d)
+	}
+	return []byte{byte(d.month), byte(d.day)}, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface
+func (d *DayMonth) UnmarshalBinary(data []byte) error {
+	if len(data) != 2 {
+		return fmt.Errorf("invalid binary date: expected 2 bytes, got %d", len(data))
+	}
+	parsed, err := MakeDayMonth(time.Month(data[0]), int(data[1]))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (d DayMonth) MarshalJSON() ([]byte, error) {
+	text, err := d.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (d *DayMonth) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
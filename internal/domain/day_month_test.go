@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDayMonthLeapDay(t *testing.T) {
+	var dm DayMonth
+	if err := json.Unmarshal([]byte(`"0229"`), &dm); err != nil {
+		t.Fatalf("Expected Feb 29 to be valid, got %v", err)
+	}
+	if dm.String() != "0229" {
+		t.Errorf("Expected '0229', got '%s'", dm.String())
+	}
+
+	if dm.ExistsIn(2025) {
+		t.Error("Feb 29 shouldn't exist in 2025")
+	}
+	if !dm.ExistsIn(2024) || dm.ExistsIn(2100) || !dm.ExistsIn(2000) {
+		t.Error("Wrong leap year rules for Feb 29")
+	}
+	if _, err := dm.In(2025, time.UTC); err == nil {
+		t.Error("Expected an error for Feb 29, 2025 instead of rolling over")
+	}
+}
+
+func TestParseDayMonthRejectsInvalidDates(t *testing.T) {
+	for _, s := range []string{"0231", "0431", "1301", "0000", "0100", "011", "ab01"} {
+		if _, err := ParseDayMonth(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+
+		var dm DayMonth
+		if err := json.Unmarshal([]byte(`"`+s+`"`), &dm); err == nil {
+			t.Errorf("Expected JSON error for %q", s)
+		}
+	}
+}
+
+func TestAllDays(t *testing.T) {
+	days := AllDays()
+	if len(days) != DaysInYear {
+		t.Fatalf("Expected %d days, got %d", DaysInYear, len(days))
+	}
+	if days[0].String() != "0101" || days[59].String() != "0229" || days[len(days)-1].String() != "1231" {
+		t.Errorf("Unexpected days: first %s, 60th %s, last %s", days[0], days[59], days[len(days)-1])
+	}
+
+	for i := 1; i < len(days); i++ {
+		if !days[i-1].Before(days[i]) || days[i-1].Next() != days[i] || days[i].Prev() != days[i-1] {
+			t.Fatalf("Days are not consecutive at %s", days[i])
+		}
+	}
+
+	if days[len(days)-1].Next() != days[0] || days[0].Prev() != days[len(days)-1] {
+		t.Error("Expected the year to wrap around")
+	}
+}
+
+func TestDayMonthBinaryRoundTrip(t *testing.T) {
+	dm, err := MakeDayMonth(time.December, 31)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := dm.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var decoded DayMonth
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if decoded != dm {
+		t.Errorf("Expected %s, got %s", dm, decoded)
+	}
+}
@@ -0,0 +1,20 @@
+// Package domaintest provides helpers for tests that build domain values.
+package domaintest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+// Day returns the DayMonth for month and day, failing the test if there
+// is no such date.
+func Day(t testing.TB, month time.Month, day int) domain.DayMonth {
+	t.Helper()
+	d, err := domain.MakeDayMonth(month, day)
+	if err != nil {
+		t.Fatalf("Invalid test date: %v", err)
+	}
+	return d
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Gender is the gender of a name. The zero value means unknown.
+type Gender string
+
+const (
+	GenderUnknown Gender = ""
+	Male          Gender = "male"
+	Female        Gender = "female"
+)
+
+// ParseGender parses "male" or "female", or their short forms "m", "f",
+// "м" and "ж"
+func ParseGender(s string) (Gender, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "male", "m", "м":
+		return Male, nil
+	case "female", "f", "ж":
+		return Female, nil
+	default:
+		return GenderUnknown, fmt.Errorf("unknown gender: %s (expected male or female)", s)
+	}
+}
+
+// Russian returns the Russian label of the gender, "" if unknown
+func (g Gender) Russian() string {
+	switch g {
+	case Male:
+		return "муж."
+	case Female:
+		return "жен."
+	default:
+		return ""
+	}
+}
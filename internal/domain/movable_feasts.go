@@ -0,0 +1,214 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// OrthodoxEaster returns the civil (Gregorian) date of Orthodox Pascha in
+// the given year. Pascha is computed in the Julian calendar and converted.
+func OrthodoxEaster(year int) DayMonth {
+	// Meeus' Julian algorithm
+	a := year % 4
+	b := year % 7
+	c := year % 19
+	d := (19*c + 15) % 30
+	e := (2*a + 4*b - d + 34) % 7
+	month := time.Month((d + e + 114) / 31)
+	day := (d+e+114)%31 + 1
+
+	easter, _, _ := DayMonth{month: month, day: day}.JulianToGregorian(year)
+	return easter
+}
+
+// WesternEaster returns the date of Western (Gregorian) Easter in the given
+// year
+func WesternEaster(year int) DayMonth {
+	// Anonymous Gregorian algorithm (Meeus/Jones/Butcher)
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := time.Month((h + l - 7*m + 114) / 31)
+	day := (h+l-7*m+114)%31 + 1
+
+	return DayMonth{month: month, day: day}
+}
+
+// FeastRuleKind says how a movable feast's date is computed
+type FeastRuleKind string
+
+const (
+	// RulePascha is Orthodox Pascha plus Offset days
+	RulePascha FeastRuleKind = "pascha"
+	// RuleWesternEaster is Western Easter plus Offset days
+	RuleWesternEaster FeastRuleKind = "western_easter"
+	// RuleSundayNearest is the Sunday nearest to Date
+	RuleSundayNearest FeastRuleKind = "sunday_nearest"
+	// RuleSundayOnOrAfter is Date if it is a Sunday, otherwise the next Sunday
+	RuleSundayOnOrAfter FeastRuleKind = "sunday_on_or_after"
+	// RuleSundayOnOrBefore is Date if it is a Sunday, otherwise the previous one
+	RuleSundayOnOrBefore FeastRuleKind = "sunday_on_or_before"
+)
+
+// FeastRule describes the date of a movable feast. Dates are new style.
+type FeastRule struct {
+	Kind   FeastRuleKind `json:"kind"`
+	Offset int           `json:"offset,omitempty"`
+	Date   *DayMonth     `json:"date,omitempty"`
+}
+
+// DateIn returns the date the rule gives in the given year
+func (r FeastRule) DateIn(year int) (DayMonth, error) {
+	switch r.Kind {
+	case RulePascha:
+		return shiftDays(OrthodoxEaster(year), year, r.Offset)
+	case RuleWesternEaster:
+		return shiftDays(WesternEaster(year), year, r.Offset)
+	case RuleSundayNearest, RuleSundayOnOrAfter, RuleSundayOnOrBefore:
+		if r.Date == nil {
+			return DayMonth{}, fmt.Errorf("rule %s needs a date", r.Kind)
+		}
+		anchor, err := r.Date.In(year, time.UTC)
+		if err != nil {
+			return DayMonth{}, err
+		}
+
+		weekday := int(anchor.Weekday())
+		var shift int
+		switch {
+		case r.Kind == RuleSundayOnOrAfter:
+			shift = (7 - weekday) % 7
+		case r.Kind == RuleSundayOnOrBefore:
+			shift = -weekday
+		case weekday <= 3:
+			shift = -weekday
+		default:
+			shift = 7 - weekday
+		}
+		return shiftDays(*r.Date, year, shift)
+	default:
+		return DayMonth{}, fmt.Errorf("unknown feast rule: %s", r.Kind)
+	}
+}
+
+// shiftDays moves d in year by days, failing if the result leaves the year
+func shiftDays(d DayMonth, year, days int) (DayMonth, error) {
+	ts, err := d.In(year, time.UTC)
+	if err != nil {
+		return DayMonth{}, err
+	}
+
+	shifted := ts.AddDate(0, 0, days)
+	if shifted.Year() != year {
+		return DayMonth{}, fmt.Errorf("%s shifted by %d days leaves %d", d, days, year)
+	}
+
+	return NewDayMonth(shifted), nil
+}
+
+// MovableFeast is a commemoration whose date changes from year to year
+type MovableFeast struct {
+	Title string    `json:"title"`
+	Rule  FeastRule `json:"rule"`
+	Names []string  `json:"names"`
+}
+
+// ParseMovableFeasts decodes a JSON list of movable feasts
+func ParseMovableFeasts(data []byte) ([]MovableFeast, error) {
+	var feasts []MovableFeast
+	if err := json.Unmarshal(data, &feasts); err != nil {
+		return nil, fmt.Errorf("error parsing movable feasts: %w", err)
+	}
+	return feasts, nil
+}
+
+// DefaultMovableFeasts returns the movable commemorations that give
+// namedays
+func DefaultMovableFeasts() []MovableFeast {
+	newMartyrs := DayMonth{month: time.February, day: 7}
+	forefathers := DayMonth{month: time.December, day: 24}
+
+	return []MovableFeast{
+		{
+			Title: "Неделя святых жен-мироносиц",
+			Rule:  FeastRule{Kind: RulePascha, Offset: 14},
+			Names: []string{"Иоанна", "Иосиф", "Мария", "Марфа", "Никодим", "Саломия", "Сусанна"},
+		},
+		{
+			Title: "Собор новомучеников и исповедников Церкви Русской",
+			Rule:  FeastRule{Kind: RuleSundayNearest, Date: &newMartyrs},
+			Names: []string{"Вениамин", "Владимир"},
+		},
+		{
+			Title: "Неделя святых праотец",
+			Rule:  FeastRule{Kind: RuleSundayOnOrAfter, Date: &forefathers},
+			Names: []string{"Авраам", "Азария", "Анания", "Даниил", "Исаак", "Иаков", "Мисаил"},
+		},
+	}
+}
+
+// YearCalendar is the namedays of one particular year: fixed namedays on
+// dates that exist in that year plus the movable feasts resolved for it
+type YearCalendar struct {
+	Year int              `json:"year"`
+	Days NamedaysDataList `json:"days"`
+}
+
+// BuildYearCalendar expands fixed namedays and movable feasts into the
+// concrete dates of year. Feb 29 namedays move to Feb 28 in non-leap years,
+// as church calendars do. The aliases, sources and metadata of the names
+// are kept, and every day gets its old style date.
+func BuildYearCalendar(year int, fixed NamedaysDataList, feasts []MovableFeast) (YearCalendar, error) {
+	byDate := make(map[DayMonth]*NamedaysData)
+	add := func(date DayMonth, n NamedaysData) {
+		day, ok := byDate[date]
+		if !ok {
+			day = &NamedaysData{Date: date}
+			byDate[date] = day
+		}
+		day.add(n)
+	}
+
+	for _, n := range fixed {
+		date := n.Date
+		if !date.ExistsIn(year) {
+			date = date.Prev()
+		}
+		add(date, n)
+	}
+
+	for _, feast := range feasts {
+		date, err := feast.Rule.DateIn(year)
+		if err != nil {
+			return YearCalendar{}, fmt.Errorf("error resolving %q: %w", feast.Title, err)
+		}
+		add(date, NamedaysData{Names: feast.Names})
+	}
+
+	dates := make([]DayMonth, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	calendar := YearCalendar{Year: year, Days: make(NamedaysDataList, 0, len(dates))}
+	for _, date := range dates {
+		calendar.Days = append(calendar.Days, *byDate[date])
+	}
+	calendar.Days = calendar.Days.WithOldStyle(year)
+
+	return calendar, nil
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEaster(t *testing.T) {
+	orthodox := map[int]string{2021: "0502", 2023: "0416", 2024: "0505", 2025: "0420", 2026: "0412"}
+	for year, expected := range orthodox {
+		if got := OrthodoxEaster(year).String(); got != expected {
+			t.Errorf("Orthodox Easter %d: expected %s, got %s", year, expected, got)
+		}
+	}
+
+	western := map[int]string{2021: "0404", 2023: "0409", 2024: "0331", 2025: "0420", 2026: "0405"}
+	for year, expected := range western {
+		if got := WesternEaster(year).String(); got != expected {
+			t.Errorf("Western Easter %d: expected %s, got %s", year, expected, got)
+		}
+	}
+}
+
+func TestFeastRuleDateIn(t *testing.T) {
+	feb7, _ := MakeDayMonth(time.February, 7)
+
+	tests := []struct {
+		rule     FeastRule
+		year     int
+		expected string
+	}{
+		{FeastRule{Kind: RulePascha, Offset: 14}, 2025, "0504"},
+		{FeastRule{Kind: RuleWesternEaster, Offset: -2}, 2024, "0329"},
+		// Feb 7, 2025 is a Friday, Feb 7, 2024 a Wednesday
+		{FeastRule{Kind: RuleSundayNearest, Date: &feb7}, 2025, "0209"},
+		{FeastRule{Kind: RuleSundayNearest, Date: &feb7}, 2024, "0204"},
+		{FeastRule{Kind: RuleSundayOnOrAfter, Date: &feb7}, 2024, "0211"},
+		{FeastRule{Kind: RuleSundayOnOrBefore, Date: &feb7}, 2025, "0202"},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.rule.DateIn(tt.year)
+		if err != nil {
+			t.Fatalf("Unexpected error for %+v: %v", tt.rule, err)
+		}
+		if got.String() != tt.expected {
+			t.Errorf("%s %d: expected %s, got %s", tt.rule.Kind, tt.year, tt.expected, got)
+		}
+	}
+}
+
+func TestBuildYearCalendar(t *testing.T) {
+	feb28, _ := MakeDayMonth(time.February, 28)
+	feb29, _ := MakeDayMonth(time.February, 29)
+	fixed := NamedaysDataList{
+		{Date: feb28, Names: []string{"Онисим"}},
+		{
+			Date:    feb29,
+			Names:   []string{"Кассиан"},
+			Aliases: map[string][]string{"Кассиан": {"Касьян"}},
+			Sources: map[string][]Attribution{"Кассиан": {{Source: "pravmir", Spelling: "Кассиан"}}},
+			Meta:    map[string]NameMeta{"Кассиан": {Gender: Male, Saints: []Saint{{Title: "прп. Кассиан Римлянин", Type: Venerable}}}},
+		},
+	}
+	feasts := []MovableFeast{{Title: "Мироносицы", Rule: FeastRule{Kind: RulePascha, Offset: 14}, Names: []string{"Марфа"}}}
+
+	calendar, err := BuildYearCalendar(2025, fixed, feasts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(calendar.Days) != 2 {
+		t.Fatalf("Expected 2 days, got %+v", calendar.Days)
+	}
+	if calendar.Days[0].Date != feb28 || len(calendar.Days[0].Names) != 2 {
+		t.Errorf("Expected Feb 29 names on Feb 28 in 2025, got %+v", calendar.Days[0])
+	}
+	kassian := calendar.Days[0]
+	if !reflect.DeepEqual(kassian.Aliases["Кассиан"], []string{"Касьян"}) || kassian.SourceCount("Кассиан") != 1 ||
+		kassian.GenderOf("Кассиан") != Male || len(kassian.SaintsOf("Кассиан")) != 1 {
+		t.Errorf("Expected the aliases, sources and metadata of Кассиан, got %+v", kassian)
+	}
+	if calendar.Days[1].Date.String() != "0504" || calendar.Days[1].OldStyle == nil || calendar.Days[1].OldStyle.String() != "0421" {
+		t.Errorf("Unexpected movable feast day: %+v", calendar.Days[1])
+	}
+}
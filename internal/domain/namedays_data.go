@@ -1,58 +1,145 @@
 package domain
 
 import (
-	"encoding/json"
 	"fmt"
-	"strconv"
-	"time"
+	"slices"
 )
 
-type DayMonth struct {
-	ts time.Time
+type NamedaysData struct {
+	Date  DayMonth `json:"date"`
+	Names []string `json:"names"`
+	// Aliases maps a canonical name in Names to the other spellings the
+	// sources used for it, e.g. "Иван": ["Иоанн"]
+	Aliases map[string][]string `json:"aliases,omitempty"`
+	// Sources maps a canonical name in Names to the sources that reported
+	// it on this date, in merged data only
+	Sources map[string][]Attribution `json:"sources,omitempty"`
+	// Meta maps a name in Names to what is known about it besides the
+	// spelling, only names with some metadata are present
+	Meta map[string]NameMeta `json:"meta,omitempty"`
+	// OldStyle is the Julian (old style) date matching Date, if known
+	OldStyle *DayMonth `json:"old_style,omitempty"`
 }
 
-func NewDayMonth(ts time.Time) DayMonth {
-	return DayMonth{ts: ts}
+// NameMeta is the metadata of a name on a date
+type NameMeta struct {
+	Gender Gender `json:"gender,omitempty"`
+	// Saints are the saints commemorated on the date the name is given
+	// after, where the source tells
+	Saints []Saint `json:"saints,omitempty"`
 }
 
-func (d DayMonth) String() string {
-	return fmt.Sprintf("%02d%02d", d.ts.Month(), d.ts.Day())
+// GenderOf returns the recorded gender of name, GenderUnknown if none is
+func (n NamedaysData) GenderOf(name string) Gender {
+	return n.Meta[name].Gender
 }
 
-// MarshalJSON implements the json.Marshaler interface
-func (d DayMonth) MarshalJSON() ([]byte, error) {
-	return json.Marshal(d.String())
+// SetGender records the gender of name. Setting GenderUnknown does nothing.
+func (n *NamedaysData) SetGender(name string, g Gender) {
+	if g == GenderUnknown {
+		return
+	}
+	if n.Meta == nil {
+		n.Meta = make(map[string]NameMeta)
+	}
+	meta := n.Meta[name]
+	meta.Gender = g
+	n.Meta[name] = meta
 }
 
-// UnmarshalJSON implements the json.Unmarshaler interface
-func (d *DayMonth) UnmarshalJSON(data []byte) error {
-	var s string
-	if err := json.Unmarshal(data, &s); err != nil {
-		return err
+// WithOldStyle returns a copy of n with OldStyle set for Date in the given
+// Gregorian year
+func (n NamedaysData) WithOldStyle(year int) (NamedaysData, error) {
+	oldStyle, _, err := n.Date.GregorianToJulian(year)
+	if err != nil {
+		return n, err
 	}
+	n.OldStyle = &oldStyle
+	return n, nil
+}
 
-	if len(s) != 4 {
-		return fmt.Errorf("invalid date format: %s, expected MMDD", s)
+// Attribution records that a source reported a name, and how it spelled it
+type Attribution struct {
+	Source   string `json:"source"`
+	Spelling string `json:"spelling"`
+}
+
+// SourceCount returns the number of distinct sources that reported name
+func (n NamedaysData) SourceCount(name string) int {
+	seen := make(map[string]bool)
+	for _, a := range n.Sources[name] {
+		seen[a.Source] = true
 	}
+	return len(seen)
+}
 
-	month, err := strconv.Atoi(s[:2])
-	if err != nil {
-		return fmt.Errorf("invalid month: %s", s[:2])
+// AllSpellings returns the names together with all their aliases
+func (n NamedaysData) AllSpellings() []string {
+	spellings := append([]string{}, n.Names...)
+	for _, name := range n.Names {
+		spellings = append(spellings, n.Aliases[name]...)
 	}
+	return spellings
+}
 
-	day, err := strconv.Atoi(s[2:])
-	if err != nil {
-		return fmt.Errorf("invalid day: %s", s[2:])
+// DateLabel formats the date in Russian, adding the old style date when
+// known, e.g. "14 января (1 января ст. ст.)"
+func (n NamedaysData) DateLabel() string {
+	if n.OldStyle == nil {
+		return n.Date.Russian()
 	}
+	return fmt.Sprintf("%s (%s ст. ст.)", n.Date.Russian(), n.OldStyle.Russian())
+}
 
-	// Use the current year, as we only need the month and day
-	d.ts = time.Date(time.Now().Year(), time.Month(month), day, 0, 0, 0, 0, time.UTC)
-	return nil
+// SaintsOf returns the saints recorded for name
+func (n NamedaysData) SaintsOf(name string) []Saint {
+	return n.Meta[name].Saints
 }
 
-type NamedaysData struct {
-	Date  DayMonth `json:"date"`
-	Names []string `json:"names"`
+// AddSaint records a saint of name, unless it is already recorded or
+// nothing is known about it
+func (n *NamedaysData) AddSaint(name string, s Saint) {
+	if s.IsZero() || slices.Contains(n.Meta[name].Saints, s) {
+		return
+	}
+	if n.Meta == nil {
+		n.Meta = make(map[string]NameMeta)
+	}
+	meta := n.Meta[name]
+	meta.Saints = append(meta.Saints, s)
+	n.Meta[name] = meta
+}
+
+// add merges the names of other into n along with their aliases, sources
+// and metadata. The date of other is ignored.
+func (n *NamedaysData) add(other NamedaysData) {
+	for _, name := range other.Names {
+		if !slices.Contains(n.Names, name) {
+			n.Names = append(n.Names, name)
+		}
+		for _, alias := range other.Aliases[name] {
+			if n.Aliases == nil {
+				n.Aliases = make(map[string][]string)
+			}
+			if !slices.Contains(n.Aliases[name], alias) {
+				n.Aliases[name] = append(n.Aliases[name], alias)
+			}
+		}
+		for _, a := range other.Sources[name] {
+			if n.Sources == nil {
+				n.Sources = make(map[string][]Attribution)
+			}
+			if !slices.Contains(n.Sources[name], a) {
+				n.Sources[name] = append(n.Sources[name], a)
+			}
+		}
+		if n.GenderOf(name) == GenderUnknown {
+			n.SetGender(name, other.GenderOf(name))
+		}
+		for _, saint := range other.SaintsOf(name) {
+			n.AddSaint(name, saint)
+		}
+	}
 }
 
 type NamedaysDataList []NamedaysData
@@ -60,3 +147,55 @@ type NamedaysDataList []NamedaysData
 func (l NamedaysDataList) Len() int {
 	return len(l)
 }
+
+// Filter returns the dates of l with only the names keep accepts. Dates
+// left without names are dropped.
+func (l NamedaysDataList) Filter(keep func(n NamedaysData, name string) bool) NamedaysDataList {
+	result := make(NamedaysDataList, 0, len(l))
+	for _, n := range l {
+		filtered := n
+		filtered.Names = nil
+		filtered.Aliases, filtered.Sources, filtered.Meta = nil, nil, nil
+		for _, name := range n.Names {
+			if !keep(n, name) {
+				continue
+			}
+			filtered.Names = append(filtered.Names, name)
+			if aliases, ok := n.Aliases[name]; ok {
+				if filtered.Aliases == nil {
+					filtered.Aliases = make(map[string][]string)
+				}
+				filtered.Aliases[name] = aliases
+			}
+			if sources, ok := n.Sources[name]; ok {
+				if filtered.Sources == nil {
+					filtered.Sources = make(map[string][]Attribution)
+				}
+				filtered.Sources[name] = sources
+			}
+			if meta, ok := n.Meta[name]; ok {
+				if filtered.Meta == nil {
+					filtered.Meta = make(map[string]NameMeta)
+				}
+				filtered.Meta[name] = meta
+			}
+		}
+		if len(filtered.Names) > 0 {
+			result = append(result, filtered)
+		}
+	}
+	return result
+}
+
+// WithOldStyle returns a copy of l with old style dates for the given
+// Gregorian year. Dates that don't exist in that year (Feb 29) keep none.
+func (l NamedaysDataList) WithOldStyle(year int) NamedaysDataList {
+	result := make(NamedaysDataList, 0, len(l))
+	for _, n := range l {
+		if withOldStyle, err := n.WithOldStyle(year); err == nil {
+			n = withOldStyle
+		}
+		result = append(result, n)
+	}
+	return result
+}
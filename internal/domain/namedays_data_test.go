@@ -2,6 +2,7 @@ package domain
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -52,3 +53,80 @@ func TestDayMonthJSONMarshaling(t *testing.T) {
 		t.Errorf("Names don't match: expected [John Jane], got %v", unmarshaled.Names)
 	}
 }
+
+func TestNamedaysDataGenderJSON(t *testing.T) {
+	data := NamedaysData{Date: NewDayMonth(time.Date(2023, time.January, 4, 0, 0, 0, 0, time.UTC)), Names: []string{"Анастасия", "Фёдор"}}
+	data.SetGender("Анастасия", Female)
+	data.SetGender("Фёдор", GenderUnknown)
+
+	jsonBytes, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("Failed to marshal JSON: %v", err)
+	}
+
+	expectedJSON := `{"date":"0104","names":["Анастасия","Фёдор"],"meta":{"Анастасия":{"gender":"female"}}}`
+	if string(jsonBytes) != expectedJSON {
+		t.Errorf("Expected JSON:\n%s\nGot:\n%s", expectedJSON, jsonBytes)
+	}
+
+	var unmarshaled NamedaysData
+	if err := json.Unmarshal(jsonBytes, &unmarshaled); err != nil {
+		t.Fatalf("Failed to unmarshal JSON: %v", err)
+	}
+	if g := unmarshaled.GenderOf("Анастасия"); g != Female {
+		t.Errorf("GenderOf(Анастасия) = %q, want %q", g, Female)
+	}
+	if g := unmarshaled.GenderOf("Фёдор"); g != GenderUnknown {
+		t.Errorf("GenderOf(Фёдор) = %q, want unknown", g)
+	}
+}
+
+func TestNamedaysDataListFilter(t *testing.T) {
+	jan4 := NewDayMonth(time.Date(2023, time.January, 4, 0, 0, 0, 0, time.UTC))
+	jan5 := NewDayMonth(time.Date(2023, time.January, 5, 0, 0, 0, 0, time.UTC))
+	list := NamedaysDataList{
+		{
+			Date:    jan4,
+			Names:   []string{"Анастасия", "Фёдор"},
+			Aliases: map[string][]string{"Фёдор": {"Феодор"}},
+			Sources: map[string][]Attribution{"Анастасия": {{Source: "calend", Spelling: "Анастасия"}}},
+			Meta:    map[string]NameMeta{"Анастасия": {Gender: Female}, "Фёдор": {Gender: Male}},
+		},
+		{Date: jan5, Names: []string{"Фёдор"}, Meta: map[string]NameMeta{"Фёдор": {Gender: Male}}},
+	}
+
+	got := list.Filter(func(n NamedaysData, name string) bool {
+		return n.GenderOf(name) == Female
+	})
+	want := NamedaysDataList{
+		{
+			Date:    jan4,
+			Names:   []string{"Анастасия"},
+			Sources: map[string][]Attribution{"Анастасия": {{Source: "calend", Spelling: "Анастасия"}}},
+			Meta:    map[string]NameMeta{"Анастасия": {Gender: Female}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Filter() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseGender(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Gender
+		wantErr bool
+	}{
+		{"male", Male, false},
+		{"F", Female, false},
+		{"ж", Female, false},
+		{"", GenderUnknown, true},
+		{"other", GenderUnknown, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseGender(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseGender(%q) = %q, %v, want %q, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Conversion between the civil (Gregorian, new style) and the Julian (old
+// style) calendars. Church calendars list commemorations by their Julian
+// date; the sources give the Gregorian one. The gap is 13 days from
+// March 1, 1900 to February 28, 2100 (Gregorian) and grows by one day on
+// every Gregorian century year that isn't a leap year.
+
+// GregorianToJulian converts d in the given Gregorian year to old style.
+// It also returns the Julian year, which differs from year for the first
+// days of January, e.g. January 7, 2025 is December 25, 2024 old style.
+func (d DayMonth) GregorianToJulian(year int) (DayMonth, int, error) {
+	if !d.ExistsIn(year) {
+		return DayMonth{}, 0, fmt.Errorf("%s doesn't exist in %d", d, year)
+	}
+
+	y, m, day := jdnToJulian(gregorianToJDN(year, d.month, d.day))
+	return DayMonth{month: m, day: day}, y, nil
+}
+
+// JulianToGregorian converts d in the given Julian year to new style.
+// It also returns the Gregorian year, which differs from year for the last
+// days of December.
+func (d DayMonth) JulianToGregorian(year int) (DayMonth, int, error) {
+	if !d.IsValid() || (d.month == time.February && d.day == 29 && year%4 != 0) {
+		return DayMonth{}, 0, fmt.Errorf("%s doesn't exist in Julian year %d", d, year)
+	}
+
+	y, m, day := jdnToGregorian(julianToJDN(year, d.month, d.day))
+	return DayMonth{month: m, day: day}, y, nil
+}
+
+// JulianOffset returns how many days the Julian calendar lags behind the
+// Gregorian one around the given date: 13 in 1900-2099 and 14 from
+// March 2100. The offset changes with the end of February of a century year.
+func JulianOffset(year int, month time.Month, day int) int {
+	return julianToJDN(year, month, day) - gregorianToJDN(year, month, day)
+}
+
+// The algorithms below convert dates to and from the Julian Day Number,
+// a continuous count of days shared by both calendars.
+
+func gregorianToJDN(year int, month time.Month, day int) int {
+	a := (14 - int(month)) / 12
+	y := year + 4800 - a
+	m := int(month) + 12*a - 3
+	return day + (153*m+2)/5 + 365*y + y/4 - y/100 + y/400 - 32045
+}
+
+func julianToJDN(year int, month time.Month, day int) int {
+	a := (14 - int(month)) / 12
+	y := year + 4800 - a
+	m := int(month) + 12*a - 3
+	return day + (153*m+2)/5 + 365*y + y/4 - 32083
+}
+
+func jdnToGregorian(jdn int) (int, time.Month, int) {
+	a := jdn + 32044
+	b := (4*a + 3) / 146097
+	c := a - 146097*b/4
+	return fromShiftedDate(b*100, c)
+}
+
+func jdnToJulian(jdn int) (int, time.Month, int) {
+	return fromShiftedDate(0, jdn+32082)
+}
+
+// fromShiftedDate finishes both JDN conversions: c is the day count from
+// March 1 of year centuries-4800
+func fromShiftedDate(centuries, c int) (int, time.Month, int) {
+	d := (4*c + 3) / 1461
+	e := c - 1461*d/4
+	m := (5*e + 2) / 153
+
+	day := e - (153*m+2)/5 + 1
+	month := m + 3 - 12*(m/10)
+	year := centuries + d - 4800 + m/10
+	return year, time.Month(month), day
+}
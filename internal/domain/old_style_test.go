@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGregorianToJulian(t *testing.T) {
+	tests := []struct {
+		year      int
+		gregorian string
+		julian    string
+		julianYr  int
+	}{
+		{2025, "0114", "0101", 2025},
+		{2025, "0107", "1225", 2024},
+		{2024, "0313", "0229", 2024},
+		{2025, "0313", "0228", 2025},
+		{2100, "0313", "0228", 2100},
+		{2100, "0314", "0229", 2100},
+		{2100, "0315", "0301", 2100},
+		{1918, "0214", "0201", 1918},
+	}
+
+	for _, tt := range tests {
+		d, err := ParseDayMonth(tt.gregorian)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		julian, julianYear, err := d.GregorianToJulian(tt.year)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s %d: %v", tt.gregorian, tt.year, err)
+		}
+		if julian.String() != tt.julian || julianYear != tt.julianYr {
+			t.Errorf("%s %d: expected %s %d, got %s %d", tt.gregorian, tt.year, tt.julian, tt.julianYr, julian, julianYear)
+		}
+
+		back, backYear, err := julian.JulianToGregorian(julianYear)
+		if err != nil || back != d || backYear != tt.year {
+			t.Errorf("%s %d: round trip gave %s %d (%v)", tt.gregorian, tt.year, back, backYear, err)
+		}
+	}
+}
+
+func TestJulianOffset(t *testing.T) {
+	if got := JulianOffset(2025, time.January, 1); got != 13 {
+		t.Errorf("Expected 13 days in 2025, got %d", got)
+	}
+	if got := JulianOffset(2100, time.February, 28); got != 13 {
+		t.Errorf("Expected 13 days before March 2100, got %d", got)
+	}
+	if got := JulianOffset(2100, time.March, 1); got != 14 {
+		t.Errorf("Expected 14 days from March 2100, got %d", got)
+	}
+}
+
+func TestNamedaysDataDateLabel(t *testing.T) {
+	d, _ := MakeDayMonth(time.January, 14)
+	n, err := NamedaysData{Date: d, Names: []string{"Василий"}}.WithOldStyle(2025)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := n.DateLabel(); got != "14 января (1 января ст. ст.)" {
+		t.Errorf("Unexpected label: %s", got)
+	}
+}
@@ -0,0 +1,98 @@
+package domain
+
+import "strings"
+
+// Commemoration is the rank a saint is commemorated in, e.g. a martyr or a
+// prophet. The zero value means unknown.
+type Commemoration string
+
+const (
+	CommemorationUnknown Commemoration = ""
+	Martyr               Commemoration = "martyr"
+	GreatMartyr          Commemoration = "great_martyr"
+	Hieromartyr          Commemoration = "hieromartyr"
+	VenerableMartyr      Commemoration = "venerable_martyr"
+	Venerable            Commemoration = "venerable"
+	Prophet              Commemoration = "prophet"
+	Apostle              Commemoration = "apostle"
+	EqualToApostles      Commemoration = "equal_to_apostles"
+	Hierarch             Commemoration = "hierarch"
+	Righteous            Commemoration = "righteous"
+	Blessed              Commemoration = "blessed"
+	Confessor            Commemoration = "confessor"
+	Unmercenary          Commemoration = "unmercenary"
+)
+
+// commemorationWords maps the abbreviations and words of the church
+// calendar that open a saint's title to the commemoration they mean
+var commemorationWords = map[string]Commemoration{
+	"мч": Martyr, "мц": Martyr, "мчч": Martyr, "мцц": Martyr,
+	"мученик": Martyr, "мученица": Martyr, "мученики": Martyr, "мученицы": Martyr,
+	"вмч": GreatMartyr, "вмц": GreatMartyr, "великомученик": GreatMartyr, "великомученица": GreatMartyr,
+	"сщмч": Hieromartyr, "священномученик": Hieromartyr,
+	"прмч": VenerableMartyr, "прмц": VenerableMartyr, "преподобномученик": VenerableMartyr, "преподобномученица": VenerableMartyr,
+	"прп": Venerable, "преподобный": Venerable, "преподобная": Venerable,
+	"прор": Prophet, "пророк": Prophet, "пророчица": Prophet,
+	"ап": Apostle, "апп": Apostle, "апостол": Apostle,
+	"равноап": EqualToApostles, "равноапостольный": EqualToApostles, "равноапостольная": EqualToApostles,
+	"свт": Hierarch, "святитель": Hierarch,
+	"прав": Righteous, "праведный": Righteous, "праведная": Righteous, "праведник": Righteous,
+	"блж": Blessed, "блаженный": Blessed, "блаженная": Blessed,
+	"исп": Confessor, "исповедник": Confessor, "исповедница": Confessor,
+	"бессрр": Unmercenary, "бессребреник": Unmercenary, "бессребреники": Unmercenary,
+}
+
+var commemorationLabels = map[Commemoration]string{
+	Martyr:          "мученик",
+	GreatMartyr:     "великомученик",
+	Hieromartyr:     "священномученик",
+	VenerableMartyr: "преподобномученик",
+	Venerable:       "преподобный",
+	Prophet:         "пророк",
+	Apostle:         "апостол",
+	EqualToApostles: "равноапостольный",
+	Hierarch:        "святитель",
+	Righteous:       "праведный",
+	Blessed:         "блаженный",
+	Confessor:       "исповедник",
+	Unmercenary:     "бессребреник",
+}
+
+// CommemorationOf returns the commemoration named by the first word of a
+// saint's title, e.g. Martyr for "мч. Вонифатий Тарсийский", or by a note
+// like "мученик"
+func CommemorationOf(title string) Commemoration {
+	fields := strings.Fields(strings.ToLower(title))
+	if len(fields) == 0 {
+		return CommemorationUnknown
+	}
+	return commemorationWords[strings.Trim(fields[0], ".,")]
+}
+
+// Russian returns the Russian name of the commemoration, "" if unknown
+func (c Commemoration) Russian() string {
+	return commemorationLabels[c]
+}
+
+// Saint is a saint commemorated on a date that a name is given after
+type Saint struct {
+	// Title is the full title, e.g. "мч. Вонифатий Тарсийский"
+	Title string        `json:"title,omitempty"`
+	Type  Commemoration `json:"type,omitempty"`
+	// URL is the page about the saint on the source's site
+	URL string `json:"url,omitempty"`
+}
+
+// IsZero reports whether nothing is known about the saint
+func (s Saint) IsZero() bool {
+	return s == Saint{}
+}
+
+// String returns the title of the saint, or the commemoration if the
+// title isn't known
+func (s Saint) String() string {
+	if s.Title != "" {
+		return s.Title
+	}
+	return s.Type.Russian()
+}
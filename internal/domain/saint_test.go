@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommemorationOf(t *testing.T) {
+	tests := []struct {
+		title string
+		want  Commemoration
+	}{
+		{"мч. Вонифатий Тарсийский", Martyr},
+		{"Сщмч. Игнатий Богоносец", Hieromartyr},
+		{"прп. Серафим Саровский", Venerable},
+		{"пророк", Prophet},
+		{"Вонифатий Тарсийский", CommemorationUnknown},
+		{"", CommemorationUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := CommemorationOf(tt.title); got != tt.want {
+			t.Errorf("CommemorationOf(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestAddSaint(t *testing.T) {
+	var n NamedaysData
+	saint := Saint{Title: "мч. Вонифатий Тарсийский", Type: Martyr, URL: "https://example.com/vonifatij/"}
+	n.AddSaint("Вонифатий", saint)
+	n.AddSaint("Вонифатий", saint)
+	n.AddSaint("Вонифатий", Saint{})
+	n.SetGender("Вонифатий", Male)
+
+	want := NameMeta{Gender: Male, Saints: []Saint{saint}}
+	if got := n.Meta["Вонифатий"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Meta = %+v, want %+v", got, want)
+	}
+
+	if s := (Saint{Type: Prophet}).String(); s != "пророк" {
+		t.Errorf("String() = %q, want the commemoration", s)
+	}
+}
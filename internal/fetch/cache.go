@@ -0,0 +1,102 @@
+package fetch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CacheMode controls how the on-disk response cache is used
+type CacheMode string
+
+const (
+	// CacheOff disables the cache, every page is downloaded
+	CacheOff CacheMode = "off"
+	// CacheReadOnly serves pages from the cache only and never touches the
+	// network, so parsers can be re-run offline. A missing page is an error.
+	CacheReadOnly CacheMode = "readonly"
+	// CacheReadWrite serves pages from the cache and stores downloaded ones
+	CacheReadWrite CacheMode = "readwrite"
+)
+
+// ErrCacheMiss is returned in read-only mode for pages that aren't cached
+var ErrCacheMiss = errors.New("page is not in the cache")
+
+// ParseCacheMode parses a cache mode name as given on the command line
+func ParseCacheMode(s string) (CacheMode, error) {
+	switch mode := CacheMode(s); mode {
+	case CacheOff, CacheReadOnly, CacheReadWrite:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown cache mode: %s (expected off, readonly or readwrite)", s)
+	}
+}
+
+// responseCache stores response bodies on disk, addressed by the SHA-256
+// of the URL they were fetched from
+type responseCache struct {
+	dir  string
+	mode CacheMode
+}
+
+// path returns the file a response for url is stored in. Files are spread
+// over subdirectories by the first byte of the hash.
+func (c *responseCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, key[:2], key+".html")
+}
+
+// load returns the cached body for url. The boolean is false on a miss.
+func (c *responseCache) load(url string) ([]byte, bool, error) {
+	if c == nil || c.mode == CacheOff {
+		return nil, false, nil
+	}
+
+	body, err := os.ReadFile(c.path(url))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading cache for %s: %w", url, err)
+	}
+
+	return body, true, nil
+}
+
+// store saves body for url. It is a no-op unless the cache is writable.
+func (c *responseCache) store(url string, body []byte) error {
+	if c == nil || c.mode != CacheReadWrite {
+		return nil
+	}
+
+	path := c.path(url)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so concurrent readers never see a
+	// partially written page
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+
+	return nil
+}
@@ -1,9 +1,12 @@
 package fetch
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"net/http"
+	"io"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -11,31 +14,53 @@ import (
 	"github.com/schollz/progressbar/v3"
 )
 
+func init() {
+	Register(Source{
+		Name:        "calend",
+		Description: "Calend.ru, one page per day with male and female names",
+		OutputFile:  "calend_namedays.json",
+		Locale:      "ru",
+		New: func(opts ...Option) Fetcher {
+			return NewCalendFetcher(opts...)
+		},
+	})
+}
+
 // CalendFetcher fetches namedays for a specific date
 type CalendFetcher struct {
-	baseURL string
-	client  *http.Client
+	baseURL     string
+	http        *httpClient
+	concurrency int
+	progress    io.Writer
 }
 
 // NewCalendFetcher creates a new instance of NamedaysFetcher
-func NewCalendFetcher() *CalendFetcher {
+func NewCalendFetcher(opts ...Option) *CalendFetcher {
+	o := newOptions(opts)
+
 	return &CalendFetcher{
-		baseURL: "https://www.calend.ru/names",
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		baseURL:     o.baseURLOr("https://www.calend.ru/names"),
+		http:        newHTTPClient(o),
+		concurrency: o.concurrency,
+		progress:    o.progress,
 	}
 }
 
-func (f *CalendFetcher) FetchAllNamedays() (domain.NamedaysDataList, error) {
-	now := time.Now()
-	currentYear := now.Year()
-
-	startDate := time.Date(currentYear, time.January, 1, 0, 0, 0, 0, time.Local)
+// calendResult is the outcome of fetching a single day
+type calendResult struct {
+	day     int
+	nameday domain.NamedaysData
+	err     error
+}
 
-	namedays := domain.NamedaysDataList{}
+// FetchAllNamedays fetches every day of the year using a pool of workers.
+// Results are returned in date order regardless of the order they arrive in.
+func (f *CalendFetcher) FetchAllNamedays(ctx context.Context) (domain.NamedaysDataList, error) {
+	year := time.Now().Year()
+	allDays := domain.AllDays()
 
-	bar := progressbar.NewOptions(365,
+	bar := progressbar.NewOptions(len(allDays),
+		progressbar.OptionSetWriter(f.progress),
 		progressbar.OptionEnableColorCodes(true),
 		progressbar.OptionShowCount(),
 		progressbar.OptionSetWidth(50),
@@ -48,53 +73,125 @@ func (f *CalendFetcher) FetchAllNamedays() (domain.NamedaysDataList, error) {
 			BarEnd:        "]",
 		}))
 
-	for day := 0; day < 365; day++ {
-		date := startDate.AddDate(0, 0, day)
-		names, err := f.fetchNamedays(date)
-		if err != nil {
-			return nil, fmt.Errorf("error fetching namedays: %w", err)
+	// workCtx is cancelled on the first failure so the other workers stop early
+	workCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	days := make(chan int)
+	results := make(chan calendResult)
+
+	go func() {
+		defer close(days)
+		for day := range allDays {
+			select {
+			case days <- day:
+			case <-workCtx.Done():
+				return
+			}
 		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < f.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for day := range days {
+				nameday, err := f.fetchNamedays(workCtx, allDays[day], year)
+				select {
+				case results <- calendResult{day: day, nameday: nameday, err: err}:
+				case <-workCtx.Done():
+					return
+				}
+			}
+		}()
+	}
 
-		namedays = append(namedays, domain.NamedaysData{
-			Date:  domain.NewDayMonth(date),
-			Names: names,
-		})
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Only this goroutine touches the bar and the collected slices
+	fetched := make([]*calendResult, len(allDays))
+	completed := 0
+	var firstErr error
+	for res := range results {
+		if res.err != nil {
+			if firstErr == nil {
+				firstErr = res.err
+				cancel()
+			}
+			continue
+		}
 
+		fetched[res.day] = &res
+		completed++
 		_ = bar.Add(1)
 	}
 
+	namedays := domain.NamedaysDataList{}
+	for _, res := range fetched {
+		if res == nil {
+			continue
+		}
+		namedays = append(namedays, res.nameday)
+	}
+
+	if err := ctx.Err(); err != nil {
+		_ = bar.Exit()
+		return namedays, fmt.Errorf("fetching interrupted after %d of %d days: %w", completed, len(allDays), err)
+	}
+	if firstErr != nil {
+		_ = bar.Exit()
+		return nil, fmt.Errorf("error fetching namedays: %w", firstErr)
+	}
+
 	_ = bar.Finish()
-	fmt.Println("Namedays loaded from Calend.ru")
+	fmt.Fprintln(f.progress, "Namedays loaded from Calend.ru")
 
 	return namedays, nil
 }
 
-// FetchNamedays fetches namedays for a specific date
-func (f *CalendFetcher) fetchNamedays(date time.Time) ([]string, error) {
-	url := fmt.Sprintf("%s/%d-%d-%d/", f.baseURL, date.Year(), date.Month(), date.Day())
-
-	resp, err := f.client.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching namedays: %w", err)
+// FetchNamedays fetches namedays for a specific date of the given year.
+// Feb 29 is taken from the next leap year when year isn't one.
+func (f *CalendFetcher) fetchNamedays(ctx context.Context, date domain.DayMonth, year int) (domain.NamedaysData, error) {
+	for !date.ExistsIn(year) {
+		year++
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received non-200 status code: %d", resp.StatusCode)
+	url := fmt.Sprintf("%s/%d-%d-%d/", f.baseURL, year, date.Month(), date.Day())
+
+	body, err := f.http.get(ctx, url)
+	if err != nil {
+		return domain.NamedaysData{}, fmt.Errorf("error fetching namedays: %w", err)
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("error parsing HTML: %w", err)
+		return domain.NamedaysData{}, fmt.Errorf("error parsing HTML: %w", err)
 	}
 
-	var names []string
+	return parseCalendNames(doc, date), nil
+}
+
+// parseCalendNames extracts male and female names from a calend.ru day
+// page, the M and F classes of the links give their gender
+func parseCalendNames(doc *goquery.Document, date domain.DayMonth) domain.NamedaysData {
+	nameday := domain.NamedaysData{Date: date}
 	doc.Find("a.title.name.M, a.title.name.F").Each(func(i int, s *goquery.Selection) {
 		name := strings.TrimSpace(s.Text())
-		if name != "" {
-			names = append(names, name)
+		if name == "" {
+			return
+		}
+
+		nameday.Names = append(nameday.Names, name)
+		if s.HasClass("F") {
+			nameday.SetGender(name, domain.Female)
+		} else {
+			nameday.SetGender(name, domain.Male)
 		}
 	})
 
-	return names, nil
+	return nameday
 }
@@ -0,0 +1,62 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+func TestParseCalendNames(t *testing.T) {
+	jan7, _ := domain.ParseDayMonth("0107")
+	assertGolden(t, "calend_day", parseCalendNames(loadFixture(t, "calend_day.html"), jan7))
+}
+
+func TestCalendFetchAllNamedays(t *testing.T) {
+	dayPage, err := os.ReadFile(filepath.Join("testdata", "calend_day.html"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	emptyPage, err := os.ReadFile(filepath.Join("testdata", "calend_empty.html"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	// Only January 7 has names, every other day is an empty page
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "-1-7/") {
+			_, _ = w.Write(dayPage)
+			return
+		}
+		_, _ = w.Write(emptyPage)
+	}))
+	defer server.Close()
+
+	opts := append(testOptions(server), WithConcurrency(8))
+	namedays, err := NewCalendFetcher(opts...).FetchAllNamedays(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(namedays) != domain.DaysInYear {
+		t.Fatalf("Expected %d days, got %d", domain.DaysInYear, len(namedays))
+	}
+
+	for i := 1; i < len(namedays); i++ {
+		if !namedays[i-1].Date.Before(namedays[i].Date) {
+			t.Fatalf("Days are out of order at %d: %s after %s", i, namedays[i].Date, namedays[i-1].Date)
+		}
+	}
+
+	if namedays[6].Date.String() != "0107" || len(namedays[6].Names) != 4 {
+		t.Errorf("Expected 4 names on 0107, got %+v", namedays[6])
+	}
+	if g := namedays[6].GenderOf("Мария"); g != domain.Female {
+		t.Errorf("Expected Мария to be female, got %q", g)
+	}
+}
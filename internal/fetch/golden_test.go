@@ -0,0 +1,93 @@
+package fetch
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata/golden")
+
+// assertGolden compares got, marshalled as indented JSON, with
+// testdata/golden/<name>.json. Run with -update to regenerate the file.
+func assertGolden(t *testing.T, name string, got any) {
+	t.Helper()
+
+	actual, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to marshal result: %v", err)
+	}
+	actual = append(actual, '\n')
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *update {
+		if err := os.WriteFile(path, actual, 0644); err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("Result doesn't match %s (run with -update to accept it):\n%s", path, actual)
+	}
+}
+
+// loadFixture parses an HTML fixture from testdata
+func loadFixture(t *testing.T, name string) *goquery.Document {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	return doc
+}
+
+// serveFixture starts a server that answers every request with the fixture
+func serveFixture(t *testing.T, name string) *httptest.Server {
+	t.Helper()
+
+	page, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(page)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// testOptions point a fetcher at server without rate limiting or retries
+func testOptions(server *httptest.Server) []Option {
+	return []Option{
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithRateLimit(0, 1),
+		WithRetryPolicy(RetryPolicy{}),
+		WithProgressOutput(io.Discard),
+	}
+}
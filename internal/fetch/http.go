@@ -0,0 +1,222 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RetryPolicy controls how transient failures (5xx, 429, connection resets,
+// timeouts) are retried
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	// BaseDelay is the delay before the first retry, doubled on every attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay. A Retry-After header may exceed it.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   30 * time.Second,
+	}
+}
+
+// backoff returns a jittered delay for the given zero-based attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Equal jitter: keep half of the delay and randomize the other half
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// StatusError is returned when a source responds with a non-200 status code
+type StatusError struct {
+	StatusCode int
+	// RetryAfter is the delay requested by the Retry-After header, if any
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("received non-200 status code: %d", e.StatusCode)
+}
+
+// httpClient is the HTTP plumbing shared by all fetchers: response caching,
+// rate limiting, retries with backoff and reading the response body
+type httpClient struct {
+	client  *http.Client
+	limiter *rate.Limiter
+	retry   RetryPolicy
+	logger  *log.Logger
+	cache   *responseCache
+}
+
+func newHTTPClient(o options) *httpClient {
+	limit := rate.Limit(o.rps)
+	if o.rps <= 0 {
+		limit = rate.Inf
+	}
+
+	return &httpClient{
+		client:  o.client,
+		limiter: rate.NewLimiter(limit, max(o.burst, 1)),
+		retry:   o.retry,
+		logger:  o.logger,
+		cache:   o.cache,
+	}
+}
+
+// get returns the response body for url, from the cache if possible.
+// Downloads retry transient failures.
+func (c *httpClient) get(ctx context.Context, url string) ([]byte, error) {
+	body, ok, err := c.cache.load(url)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return body, nil
+	}
+	if c.cache != nil && c.cache.mode == CacheReadOnly {
+		return nil, fmt.Errorf("%s: %w", url, ErrCacheMiss)
+	}
+
+	body, err = c.download(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.cache.store(url, body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// download fetches url from the network, retrying transient failures
+func (c *httpClient) download(ctx context.Context, url string) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("waiting for rate limiter: %w", err)
+		}
+
+		body, err := c.getOnce(ctx, url)
+		if err == nil {
+			return body, nil
+		}
+
+		if attempt >= c.retry.MaxRetries || ctx.Err() != nil || !isRetryable(err) {
+			return nil, err
+		}
+
+		delay := c.retry.backoff(attempt)
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > delay {
+			delay = statusErr.RetryAfter
+		}
+
+		c.logger.Printf("retrying %s in %s (retry %d of %d): %v", url, delay.Round(time.Millisecond), attempt+1, c.retry.MaxRetries, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *httpClient) getOnce(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response from %s: %w", url, err)
+	}
+
+	return body, nil
+}
+
+// isRetryable reports whether err is worth another attempt
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as
+// an HTTP date. It returns zero if the header is missing or invalid.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+
+	return 0
+}
@@ -0,0 +1,109 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPClientRetriesTransientFailures(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := newHTTPClient(newOptions([]Option{
+		WithRateLimit(0, 1),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}),
+		WithLogger(log.New(io.Discard, "", 0)),
+	}))
+
+	body, err := client.get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if string(body) != "ok" {
+		t.Errorf("Expected body 'ok', got '%s'", body)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestHTTPClientDoesNotRetryClientErrors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := newHTTPClient(newOptions([]Option{
+		WithRateLimit(0, 1),
+		WithLogger(log.New(io.Discard, "", 0)),
+	}))
+
+	if _, err := client.get(context.Background(), server.URL); err == nil {
+		t.Fatal("Expected an error for 404")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("7"); got != 7*time.Second {
+		t.Errorf("Expected 7s, got %s", got)
+	}
+	if got := parseRetryAfter(""); got != 0 {
+		t.Errorf("Expected 0 for empty header, got %s", got)
+	}
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got <= 0 || got > time.Minute {
+		t.Errorf("Expected a delay up to 1m for %s, got %s", future, got)
+	}
+}
+
+func TestHTTPClientCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte("page"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	readWrite := newHTTPClient(newOptions([]Option{WithRateLimit(0, 1), WithCache(dir, CacheReadWrite)}))
+	for i := 0; i < 2; i++ {
+		if _, err := readWrite.get(context.Background(), server.URL); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected the second read to come from the cache, got %d requests", requests)
+	}
+
+	readOnly := newHTTPClient(newOptions([]Option{WithCache(dir, CacheReadOnly)}))
+	body, err := readOnly.get(context.Background(), server.URL)
+	if err != nil || string(body) != "page" {
+		t.Errorf("Expected cached 'page', got '%s' (%v)", body, err)
+	}
+	if _, err := readOnly.get(context.Background(), server.URL+"/missing"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Expected ErrCacheMiss, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected read-only mode to stay offline, got %d requests", requests)
+	}
+}
@@ -1,7 +1,30 @@
 package fetch
 
-import "github.com/kvloginov/namedays/internal/domain"
+import (
+	"context"
 
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+// Fetcher loads namedays for the whole year from a single source.
+// Implementations must carry ctx into every outgoing request and, when ctx
+// is cancelled, return the namedays collected so far together with an
+// error wrapping ctx.Err().
 type Fetcher interface {
-	FetchAllNamedays() (domain.NamedaysDataList, error)
+	FetchAllNamedays(ctx context.Context) (domain.NamedaysDataList, error)
+}
+
+// Report explains how a fetcher produced its namedays, e.g. which parsing
+// strategy found each date. It is saved as JSON.
+type Report interface {
+	// Summary describes the report in one line for the log
+	Summary() string
+}
+
+// Reporter is a Fetcher that can report how it produced the namedays
+type Reporter interface {
+	Fetcher
+	// FetchWithReport is like FetchAllNamedays but also returns the report.
+	// The report is nil if err is not.
+	FetchWithReport(ctx context.Context) (domain.NamedaysDataList, Report, error)
 }
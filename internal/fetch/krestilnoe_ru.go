@@ -1,8 +1,9 @@
 package fetch
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
@@ -12,38 +13,44 @@ import (
 	"github.com/kvloginov/namedays/internal/domain"
 )
 
+func init() {
+	Register(Source{
+		Name:        "krestilnoe",
+		Description: "Krestilnoe.ru, church calendar of names for the whole year",
+		OutputFile:  "krestilnoe_namedays.json",
+		Locale:      "ru",
+		New: func(opts ...Option) Fetcher {
+			return NewKrestilnoeFetcher(opts...)
+		},
+	})
+}
+
 type KrestilnoeFetcher struct {
 	baseURL string
-	client  *http.Client
+	http    *httpClient
 }
 
-func NewKrestilnoeFetcher() *KrestilnoeFetcher {
+func NewKrestilnoeFetcher(opts ...Option) *KrestilnoeFetcher {
+	o := newOptions(opts)
+
 	return &KrestilnoeFetcher{
-		baseURL: "https://www.krestilnoe.ru/svyattsy-kalendar-god/",
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		baseURL: o.baseURLOr("https://www.krestilnoe.ru/svyattsy-kalendar-god/"),
+		http:    newHTTPClient(o),
 	}
 }
 
-func (f *KrestilnoeFetcher) FetchAllNamedays() (domain.NamedaysDataList, error) {
-	resp, err := f.client.Get(f.baseURL)
+func (f *KrestilnoeFetcher) FetchAllNamedays(ctx context.Context) (domain.NamedaysDataList, error) {
+	body, err := f.http.get(ctx, f.baseURL)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching krestilnoe.ru: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received non-200 status code: %d", resp.StatusCode)
-	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("error parsing HTML: %w", err)
 	}
 
 	namedays := domain.NamedaysDataList{}
-	currentYear := time.Now().Year()
 
 	// Find all paragraphs with calendar data
 	// Data is inside <p> tags with formatting through <br>
@@ -60,7 +67,7 @@ func (f *KrestilnoeFetcher) FetchAllNamedays() (domain.NamedaysDataList, error)
 
 				if monthNum > 0 {
 					// Parse dates and names for this month
-					monthNamedays := parseMonthNamedays(html, monthNum, currentYear)
+					monthNamedays := parseMonthNamedays(html, monthNum, f.baseURL)
 					namedays = append(namedays, monthNamedays...)
 				}
 			}
@@ -108,8 +115,10 @@ func extractMonthNumber(monthTitle string) int {
 	return 0
 }
 
-// parseMonthNamedays parses the text with names for a month
-func parseMonthNamedays(text string, monthNum, year int) []domain.NamedaysData {
+// parseMonthNamedays parses the text with names for a month. Names linked
+// to the pages of saints get them, relative links are resolved against
+// base.
+func parseMonthNamedays(text string, monthNum int, base string) []domain.NamedaysData {
 	// HTML contains <br> between days, which we need to correctly process
 	// First, clean the text from extra formatting and normalize spaces
 	text = strings.TrimSpace(text)
@@ -120,7 +129,14 @@ func parseMonthNamedays(text string, monthNum, year int) []domain.NamedaysData {
 	result := []domain.NamedaysData{}
 
 	for _, entry := range dayEntries {
-		entry = strings.TrimSpace(entry)
+		// Links are collected before the tags are dropped
+		fragment, err := goquery.NewDocumentFromReader(strings.NewReader(entry))
+		if err != nil {
+			continue
+		}
+		links := saintLinks(fragment.Selection, base)
+
+		entry = strings.TrimSpace(fragment.Text())
 		if entry == "" {
 			continue
 		}
@@ -135,12 +151,15 @@ func parseMonthNamedays(text string, monthNum, year int) []domain.NamedaysData {
 		}
 
 		day, err := strconv.Atoi(matches[1])
-		if err != nil || day < 1 || day > 31 {
+		if err != nil {
 			continue
 		}
 
-		// Create the date
-		date := time.Date(year, time.Month(monthNum), day, 0, 0, 0, 0, time.Local)
+		// Create the date, skipping impossible ones like April 31
+		date, err := domain.MakeDayMonth(time.Month(monthNum), day)
+		if err != nil {
+			continue
+		}
 
 		// Extract names, separated by commas
 		namesStr := matches[2]
@@ -169,10 +188,12 @@ func parseMonthNamedays(text string, monthNum, year int) []domain.NamedaysData {
 		}
 
 		if len(names) > 0 {
-			result = append(result, domain.NamedaysData{
-				Date:  domain.NewDayMonth(date),
-				Names: names,
-			})
+			nameday := domain.NamedaysData{Date: date, Names: names}
+			for _, name := range names {
+				_, note := splitNote(name)
+				addSaint(&nameday, name, note, links)
+			}
+			result = append(result, nameday)
 		}
 	}
 
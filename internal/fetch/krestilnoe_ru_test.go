@@ -0,0 +1,42 @@
+package fetch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+func TestKrestilnoeFetchAllNamedays(t *testing.T) {
+	server := serveFixture(t, "krestilnoe.html")
+
+	namedays, err := NewKrestilnoeFetcher(testOptions(server)...).FetchAllNamedays(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	assertGolden(t, "krestilnoe", namedays)
+}
+
+func TestParseMonthNamedays(t *testing.T) {
+	html := "1 января: Илья, и иные<br/>\n2 января: Иван, <a href=\"/svyatye/sergij/\" title=\"прп. Сергий\">Сергий</a> (Серге&#1081;)<br/>\nбез даты: Прочие<br/>\n40 января: Никто"
+
+	namedays := parseMonthNamedays(html, 1, "https://www.krestilnoe.ru/")
+	if len(namedays) != 2 {
+		t.Fatalf("Expected 2 dates, got %d: %v", len(namedays), namedays)
+	}
+
+	if namedays[0].Date.String() != "0101" || len(namedays[0].Names) != 1 || namedays[0].Names[0] != "Илья" {
+		t.Errorf("Unexpected first entry: %+v", namedays[0])
+	}
+	if namedays[1].Date.String() != "0102" || len(namedays[1].Names) != 2 {
+		t.Errorf("Unexpected second entry: %+v", namedays[1])
+	}
+
+	saints := namedays[1].SaintsOf("Сергий (Сергей)")
+	expected := []domain.Saint{{Title: "прп. Сергий", Type: domain.Venerable, URL: "https://www.krestilnoe.ru/svyatye/sergij/"}}
+	if !reflect.DeepEqual(saints, expected) {
+		t.Errorf("Expected saints %+v, got %+v", expected, saints)
+	}
+}
@@ -0,0 +1,126 @@
+package fetch
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	defaultConcurrency = 4
+	defaultRPS         = 2
+	defaultBurst       = 4
+)
+
+// Option configures a fetcher. Options that don't apply to a particular
+// source (e.g. concurrency for single-page sources) are ignored by it.
+type Option func(*options)
+
+type options struct {
+	baseURL     string
+	client      *http.Client
+	concurrency int
+	rps         float64
+	burst       int
+	retry       RetryPolicy
+	logger      *log.Logger
+	cache       *responseCache
+	progress    io.Writer
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		concurrency: defaultConcurrency,
+		rps:         defaultRPS,
+		burst:       defaultBurst,
+		retry:       DefaultRetryPolicy(),
+		logger:      log.Default(),
+		progress:    os.Stdout,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithBaseURL overrides the URL a fetcher downloads pages from, e.g. to
+// point it at an httptest.Server
+func WithBaseURL(url string) Option {
+	return func(o *options) {
+		o.baseURL = url
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for downloads
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *options) {
+		if c != nil {
+			o.client = c
+		}
+	}
+}
+
+// WithConcurrency sets how many pages are fetched in parallel
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// WithRateLimit limits requests to the source host to rps per second with
+// bursts of up to burst requests. A non-positive rps disables the limit.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(o *options) {
+		o.rps = rps
+		o.burst = max(burst, 1)
+	}
+}
+
+// WithRetryPolicy sets how transient HTTP failures are retried
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = p
+	}
+}
+
+// WithLogger sets the logger used to report retries
+func WithLogger(l *log.Logger) Option {
+	return func(o *options) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// WithCache stores and serves responses from dir according to mode
+func WithCache(dir string, mode CacheMode) Option {
+	return func(o *options) {
+		o.cache = &responseCache{dir: dir, mode: mode}
+	}
+}
+
+// WithProgressOutput sets where progress bars are drawn, io.Discard hides them
+func WithProgressOutput(w io.Writer) Option {
+	return func(o *options) {
+		if w != nil {
+			o.progress = w
+		}
+	}
+}
+
+// baseURLOr returns the configured base URL or def if none was set
+func (o options) baseURLOr(def string) string {
+	if o.baseURL != "" {
+		return o.baseURL
+	}
+	return def
+}
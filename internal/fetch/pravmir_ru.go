@@ -1,72 +1,194 @@
 package fetch
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"net/http"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/names"
 )
 
+func init() {
+	Register(Source{
+		Name:        "pravmir",
+		Description: "Pravmir.ru, Orthodox namedays calendar",
+		OutputFile:  "pravmir_namedays.json",
+		Locale:      "ru",
+		New: func(opts ...Option) Fetcher {
+			return NewPravmirFetcher(opts...)
+		},
+	})
+}
+
 // PravmirFetcher structure for parsing data from pravmir.ru
 type PravmirFetcher struct {
 	baseURL string
-	client  *http.Client
+	http    *httpClient
 }
 
 // NewPravmirFetcher creates a new instance of PravmirFetcher
-func NewPravmirFetcher() *PravmirFetcher {
+func NewPravmirFetcher(opts ...Option) *PravmirFetcher {
+	o := newOptions(opts)
+
 	return &PravmirFetcher{
-		baseURL: "https://www.pravmir.ru/pravoslavnyj-kalendar-imenin/",
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		baseURL: o.baseURLOr("https://www.pravmir.ru/pravoslavnyj-kalendar-imenin/"),
+		http:    newHTTPClient(o),
+	}
+}
+
+// PravmirStrategy names a way of finding namedays on the pravmir.ru page
+type PravmirStrategy string
+
+const (
+	PravmirTables      PravmirStrategy = "tables"
+	PravmirTextBlocks  PravmirStrategy = "text_blocks"
+	PravmirMonthBlocks PravmirStrategy = "month_blocks"
+)
+
+// PravmirReport explains how the pravmir.ru page was parsed
+type PravmirReport struct {
+	// Rows is how many rows each strategy found, before merging
+	Rows map[PravmirStrategy]int `json:"rows"`
+	// Dates lists the strategies that contributed names to each date, in
+	// the order the strategies ran
+	Dates map[domain.DayMonth][]PravmirStrategy `json:"dates"`
+}
+
+// pravmirStrategies lists the strategies in the order they run
+var pravmirStrategies = []PravmirStrategy{PravmirTables, PravmirTextBlocks, PravmirMonthBlocks}
+
+// Summary lists the rows found by each strategy and the number of dates
+func (r PravmirReport) Summary() string {
+	var counts []string
+	for _, s := range pravmirStrategies {
+		if rows, ok := r.Rows[s]; ok {
+			counts = append(counts, fmt.Sprintf("%s %d", s, rows))
+		}
 	}
+	return fmt.Sprintf("rows found per strategy: %s; %d dates", strings.Join(counts, ", "), len(r.Dates))
 }
 
 // FetchAllNamedays gets all namedays from pravmir.ru
-func (f *PravmirFetcher) FetchAllNamedays() (domain.NamedaysDataList, error) {
-	resp, err := f.client.Get(f.baseURL)
+func (f *PravmirFetcher) FetchAllNamedays(ctx context.Context) (domain.NamedaysDataList, error) {
+	namedays, _, err := f.fetch(ctx)
+	return namedays, err
+}
+
+// FetchWithReport gets all namedays from pravmir.ru, one entry per date,
+// and a PravmirReport of which parsing strategy produced them
+func (f *PravmirFetcher) FetchWithReport(ctx context.Context) (domain.NamedaysDataList, Report, error) {
+	namedays, report, err := f.fetch(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching pravmir.ru: %w", err)
+		return nil, nil, err
 	}
-	defer resp.Body.Close()
+	return namedays, report, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received non-200 status code: %d", resp.StatusCode)
+func (f *PravmirFetcher) fetch(ctx context.Context) (domain.NamedaysDataList, PravmirReport, error) {
+	body, err := f.http.get(ctx, f.baseURL)
+	if err != nil {
+		return nil, PravmirReport{}, fmt.Errorf("error fetching pravmir.ru: %w", err)
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("error parsing HTML: %w", err)
+		return nil, PravmirReport{}, fmt.Errorf("error parsing HTML: %w", err)
 	}
 
-	namedays := domain.NamedaysDataList{}
-	currentYear := time.Now().Year()
+	namedays, report := f.parse(doc)
+	return namedays, report, nil
+}
 
-	// 1. Try to find data in tables
-	tableNamedays := f.parseFromTables(doc, currentYear)
-	namedays = append(namedays, tableNamedays...)
+// parse runs the parsing strategies on the page and merges their rows
+func (f *PravmirFetcher) parse(doc *goquery.Document) (domain.NamedaysDataList, PravmirReport) {
+	rows := []pravmirRows{
+		// 1. Try to find data in tables
+		{PravmirTables, f.parseFromTables(doc)},
+	}
 
 	// 2. If there's not enough data in tables, look in other formats
-	if len(namedays) < 200 { // Expect more records for a full year
-		// Search in text blocks of main content
-		textBlockNamedays := f.parseFromTextBlocks(doc, currentYear)
-		namedays = append(namedays, textBlockNamedays...)
-
-		// Search in month blocks (in different possible formats)
-		monthBlockNamedays := f.parseFromMonthBlocks(doc, currentYear)
-		namedays = append(namedays, monthBlockNamedays...)
+	if len(rows[0].namedays) < 200 { // Expect more records for a full year
+		rows = append(rows,
+			// Search in text blocks of main content
+			pravmirRows{PravmirTextBlocks, f.parseFromTextBlocks(doc)},
+			// Search in month blocks (in different possible formats)
+			pravmirRows{PravmirMonthBlocks, f.parseFromMonthBlocks(doc)},
+		)
 	}
 
-	return namedays, nil
+	return mergePravmirRows(rows)
+}
+
+// pravmirRows are the rows found by one strategy
+type pravmirRows struct {
+	strategy PravmirStrategy
+	namedays domain.NamedaysDataList
+}
+
+// mergePravmirRows collapses the rows of all strategies into one entry per
+// date. Names keep the order they were first seen in, and spellings that
+// differ only by case or ё are kept once, with the saints of all of them.
+func mergePravmirRows(rows []pravmirRows) (domain.NamedaysDataList, PravmirReport) {
+	report := PravmirReport{
+		Rows:  make(map[PravmirStrategy]int),
+		Dates: make(map[domain.DayMonth][]PravmirStrategy),
+	}
+
+	byDate := make(map[domain.DayMonth]*domain.NamedaysData)
+	// seen holds the spelling kept for every folded name of a date
+	seen := make(map[domain.DayMonth]map[string]string)
+
+	for _, r := range rows {
+		report.Rows[r.strategy] = len(r.namedays)
+
+		for _, row := range r.namedays {
+			entry, ok := byDate[row.Date]
+			if !ok {
+				entry = &domain.NamedaysData{Date: row.Date}
+				byDate[row.Date] = entry
+				seen[row.Date] = make(map[string]string)
+			}
+
+			added := false
+			for _, name := range row.Names {
+				key := names.Fold(name)
+				kept, ok := seen[row.Date][key]
+				if !ok {
+					kept = name
+					seen[row.Date][key] = name
+					entry.Names = append(entry.Names, name)
+					added = true
+				}
+				for _, saint := range row.SaintsOf(name) {
+					entry.AddSaint(kept, saint)
+				}
+			}
+
+			if strategies := report.Dates[row.Date]; added && !slices.Contains(strategies, r.strategy) {
+				report.Dates[row.Date] = append(strategies, r.strategy)
+			}
+		}
+	}
+
+	namedays := make(domain.NamedaysDataList, 0, len(byDate))
+	for _, entry := range byDate {
+		namedays = append(namedays, *entry)
+	}
+	slices.SortFunc(namedays, func(a, b domain.NamedaysData) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	return namedays, report
 }
 
 // parseFromTables tries to extract data from HTML tables
-func (f *PravmirFetcher) parseFromTables(doc *goquery.Document, year int) domain.NamedaysDataList {
+func (f *PravmirFetcher) parseFromTables(doc *goquery.Document) domain.NamedaysDataList {
 	result := domain.NamedaysDataList{}
 
 	// Find and process tables with namedays
@@ -108,14 +230,15 @@ func (f *PravmirFetcher) parseFromTables(doc *goquery.Document, year int) domain
 				}
 
 				namesText := strings.TrimSpace(namesCell.Text())
-				names := parseNames(namesText)
+				names, notes := parseNames(namesText)
+
+				date, err := domain.MakeDayMonth(time.Month(month), day)
+				if err != nil {
+					return
+				}
 
 				if len(names) > 0 {
-					date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
-					result = append(result, domain.NamedaysData{
-						Date:  domain.NewDayMonth(date),
-						Names: names,
-					})
+					result = append(result, newPravmirNameday(date, names, notes, saintLinks(namesCell, f.baseURL)))
 				}
 			})
 		}
@@ -125,7 +248,7 @@ func (f *PravmirFetcher) parseFromTables(doc *goquery.Document, year int) domain
 }
 
 // parseFromTextBlocks tries to extract data from text blocks
-func (f *PravmirFetcher) parseFromTextBlocks(doc *goquery.Document, year int) domain.NamedaysDataList {
+func (f *PravmirFetcher) parseFromTextBlocks(doc *goquery.Document) domain.NamedaysDataList {
 	result := domain.NamedaysDataList{}
 
 	// Search for blocks with namedays in the main content
@@ -160,14 +283,15 @@ func (f *PravmirFetcher) parseFromTextBlocks(doc *goquery.Document, year int) do
 					continue
 				}
 
-				names := parseNames(namesStr)
+				names, notes := parseNames(namesStr)
+
+				date, err := domain.MakeDayMonth(time.Month(month), day)
+				if err != nil {
+					continue
+				}
 
 				if len(names) > 0 {
-					date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
-					result = append(result, domain.NamedaysData{
-						Date:  domain.NewDayMonth(date),
-						Names: names,
-					})
+					result = append(result, newPravmirNameday(date, names, notes, saintLinks(p, f.baseURL)))
 				}
 			}
 		})
@@ -182,7 +306,7 @@ func (f *PravmirFetcher) parseFromTextBlocks(doc *goquery.Document, year int) do
 }
 
 // parseFromMonthBlocks tries to extract data from month blocks
-func (f *PravmirFetcher) parseFromMonthBlocks(doc *goquery.Document, year int) domain.NamedaysDataList {
+func (f *PravmirFetcher) parseFromMonthBlocks(doc *goquery.Document) domain.NamedaysDataList {
 	result := domain.NamedaysDataList{}
 
 	// Search for month blocks (possible formats of the site)
@@ -241,14 +365,15 @@ func (f *PravmirFetcher) parseFromMonthBlocks(doc *goquery.Document, year int) d
 					continue
 				}
 
-				names := parseNames(namesStr)
+				names, notes := parseNames(namesStr)
+
+				date, err := domain.MakeDayMonth(time.Month(month), day)
+				if err != nil {
+					continue
+				}
 
 				if len(names) > 0 {
-					date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
-					result = append(result, domain.NamedaysData{
-						Date:  domain.NewDayMonth(date),
-						Names: names,
-					})
+					result = append(result, newPravmirNameday(date, names, notes, saintLinks(monthBlock, f.baseURL)))
 				}
 			}
 		})
@@ -327,12 +452,14 @@ func getMonthNumber(monthStr string) int {
 	return 0
 }
 
-// parseNames extracts names from a string and returns them as an array
-func parseNames(namesStr string) []string {
+// parseNames extracts names from a string and returns them as an array,
+// together with the explanations in parentheses after them by name
+func parseNames(namesStr string) ([]string, map[string]string) {
 	// Split names by comma
 	namesSplit := strings.Split(namesStr, ",")
 
 	var cleanNames []string
+	notes := make(map[string]string)
 	for _, name := range namesSplit {
 		name = strings.TrimSpace(name)
 		name = strings.ReplaceAll(name, ".", "")
@@ -346,13 +473,24 @@ func parseNames(namesStr string) []string {
 			!strings.Contains(name, "именины") &&
 			!strings.Contains(name, "праздник") &&
 			!strings.Contains(name, "день памяти") {
-			// Remove possible explanations in parentheses
-			if idx := strings.Index(name, "("); idx > 0 {
-				name = strings.TrimSpace(name[:idx])
+			// Keep possible explanations in parentheses apart
+			name, note := splitNote(name)
+			if note != "" {
+				notes[name] = note
 			}
 			cleanNames = append(cleanNames, name)
 		}
 	}
 
-	return cleanNames
+	return cleanNames, notes
+}
+
+// newPravmirNameday creates the entry of a row, with the saints the names
+// link to or whose commemoration their notes give
+func newPravmirNameday(date domain.DayMonth, names []string, notes map[string]string, links map[string]domain.Saint) domain.NamedaysData {
+	nameday := domain.NamedaysData{Date: date, Names: names}
+	for _, name := range names {
+		addSaint(&nameday, name, notes[name], links)
+	}
+	return nameday
 }
@@ -0,0 +1,87 @@
+package fetch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+func TestPravmirParsers(t *testing.T) {
+	doc := loadFixture(t, "pravmir.html")
+	f := NewPravmirFetcher()
+
+	assertGolden(t, "pravmir_tables", f.parseFromTables(doc))
+	assertGolden(t, "pravmir_text_blocks", f.parseFromTextBlocks(doc))
+	assertGolden(t, "pravmir_month_blocks", f.parseFromMonthBlocks(doc))
+}
+
+func TestPravmirFetchAllNamedays(t *testing.T) {
+	server := serveFixture(t, "pravmir.html")
+
+	namedays, err := NewPravmirFetcher(testOptions(server)...).FetchAllNamedays(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	assertGolden(t, "pravmir", namedays)
+}
+
+func TestPravmirFetchWithReport(t *testing.T) {
+	server := serveFixture(t, "pravmir.html")
+
+	var fetcher Fetcher = NewPravmirFetcher(testOptions(server)...)
+	reporter, ok := fetcher.(Reporter)
+	if !ok {
+		t.Fatal("Expected PravmirFetcher to be a Reporter")
+	}
+
+	namedays, report, err := reporter.FetchWithReport(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	seen := make(map[domain.DayMonth]bool)
+	for _, n := range namedays {
+		if seen[n.Date] {
+			t.Errorf("Date %s appears more than once", n.Date)
+		}
+		seen[n.Date] = true
+	}
+
+	assertGolden(t, "pravmir_report", report)
+}
+
+func TestMergePravmirRows(t *testing.T) {
+	jan1, _ := domain.ParseDayMonth("0101")
+	jan2, _ := domain.ParseDayMonth("0102")
+
+	namedays, report := mergePravmirRows([]pravmirRows{
+		{PravmirTables, domain.NamedaysDataList{{Date: jan2, Names: []string{"Сергий", "Пётр"}}}},
+		{PravmirTextBlocks, domain.NamedaysDataList{
+			{Date: jan2, Names: []string{"Петр", "Иоанн"}},
+			{Date: jan1, Names: []string{"Илья"}},
+		}},
+		{PravmirMonthBlocks, domain.NamedaysDataList{{Date: jan1, Names: []string{"Илья"}}}},
+	})
+
+	expected := domain.NamedaysDataList{
+		{Date: jan1, Names: []string{"Илья"}},
+		{Date: jan2, Names: []string{"Сергий", "Пётр", "Иоанн"}},
+	}
+	if !reflect.DeepEqual(namedays, expected) {
+		t.Errorf("Expected %v, got %v", expected, namedays)
+	}
+
+	expectedReport := PravmirReport{
+		Rows: map[PravmirStrategy]int{PravmirTables: 1, PravmirTextBlocks: 2, PravmirMonthBlocks: 1},
+		Dates: map[domain.DayMonth][]PravmirStrategy{
+			jan1: {PravmirTextBlocks},
+			jan2: {PravmirTables, PravmirTextBlocks},
+		},
+	}
+	if !reflect.DeepEqual(report, expectedReport) {
+		t.Errorf("Expected %+v, got %+v", expectedReport, report)
+	}
+}
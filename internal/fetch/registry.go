@@ -0,0 +1,68 @@
+package fetch
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Source describes a namedays source that can be fetched
+type Source struct {
+	// Name identifies the source on the command line, e.g. "calend"
+	Name string
+	// Description is a one-line human readable description
+	Description string
+	// OutputFile is the default file name for the fetched data, relative
+	// to the data directory
+	OutputFile string
+	// Locale is the language of the names the source provides, e.g. "ru"
+	Locale string
+	// New creates a fetcher for the source
+	New func(opts ...Option) Fetcher
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Source)
+)
+
+// Register makes a source available by name. It panics if the source is
+// incomplete or its name is already taken, as this is a programming error.
+func Register(s Source) {
+	if s.Name == "" || s.OutputFile == "" || s.New == nil {
+		panic(fmt.Sprintf("fetch: incomplete source %q", s.Name))
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[s.Name]; ok {
+		panic(fmt.Sprintf("fetch: source %q registered twice", s.Name))
+	}
+	registry[s.Name] = s
+}
+
+// Lookup returns the registered source with the given name
+func Lookup(name string) (Source, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	s, ok := registry[name]
+	return s, ok
+}
+
+// Sources returns all registered sources sorted by name
+func Sources() []Source {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	sources := make([]Source, 0, len(registry))
+	for _, s := range registry {
+		sources = append(sources, s)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Name < sources[j].Name
+	})
+
+	return sources
+}
@@ -0,0 +1,28 @@
+package fetch
+
+import "testing"
+
+func TestBuiltinSourcesAreRegistered(t *testing.T) {
+	var names []string
+	for _, s := range Sources() {
+		names = append(names, s.Name)
+	}
+
+	expected := []string{"calend", "krestilnoe", "pravmir"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected sources %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected sources %v, got %v", expected, names)
+		}
+	}
+
+	source, ok := Lookup("pravmir")
+	if !ok || source.OutputFile != "pravmir_namedays.json" {
+		t.Errorf("Unexpected pravmir source: %+v", source)
+	}
+	if _, ok := source.New().(*PravmirFetcher); !ok {
+		t.Error("Expected the pravmir source to create a PravmirFetcher")
+	}
+}
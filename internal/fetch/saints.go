@@ -0,0 +1,68 @@
+package fetch
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/names"
+)
+
+// saintLinks collects the links in sel by the folded name they are the
+// text of, e.g. <a href="/vonifatij/" title="мч. Вонифатий Тарсийский">
+// Вонифатий</a>. The title attribute, if any, is the saint's full title.
+// Relative links are resolved against base.
+func saintLinks(sel *goquery.Selection, base string) map[string]domain.Saint {
+	baseURL, _ := url.Parse(base)
+
+	links := make(map[string]domain.Saint)
+	sel.Find("a[href]").Each(func(i int, a *goquery.Selection) {
+		name, _ := splitNote(a.Text())
+		href, _ := a.Attr("href")
+		ref, err := url.Parse(strings.TrimSpace(href))
+		if name == "" || err != nil {
+			return
+		}
+		if baseURL != nil {
+			ref = baseURL.ResolveReference(ref)
+		}
+
+		title, _ := a.Attr("title")
+		title = strings.Join(strings.Fields(title), " ")
+		key := names.Fold(name)
+		if _, ok := links[key]; !ok {
+			links[key] = domain.Saint{Title: title, Type: domain.CommemorationOf(title), URL: ref.String()}
+		}
+	})
+	return links
+}
+
+// splitNote splits "Вонифатий (мученик)" into the name and the note in
+// parentheses
+func splitNote(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	open := strings.Index(text, "(")
+	if open <= 0 {
+		return text, ""
+	}
+
+	note := text[open+1:]
+	if end := strings.Index(note, ")"); end >= 0 {
+		note = note[:end]
+	}
+	return strings.TrimSpace(text[:open]), strings.TrimSpace(note)
+}
+
+// addSaint records the saint of a name found on a page: the one it links
+// to, with the commemoration of its note, like "мученик", where the link
+// doesn't tell. A name that kept its note, like "Авдий (Авид)", is looked
+// up without it.
+func addSaint(n *domain.NamedaysData, name, note string, links map[string]domain.Saint) {
+	linked, _ := splitNote(name)
+	saint := links[names.Fold(linked)]
+	if saint.Type == domain.CommemorationUnknown {
+		saint.Type = domain.CommemorationOf(note)
+	}
+	n.AddSaint(name, saint)
+}
@@ -0,0 +1,211 @@
+package ical
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/names"
+	"github.com/kvloginov/namedays/internal/query"
+)
+
+// baseYear is the year of the first occurrence of every event. It is a leap
+// year so that Feb 29 namedays start on a real date.
+const baseYear = 2000
+
+// Event is an all-day nameday, repeated yearly unless the calendar has a
+// Year
+type Event struct {
+	Date domain.DayMonth
+	// Name is the name as the dataset lists it
+	Name string
+	// Title is the name shown in the event, e.g. Name in Latin script. Name
+	// is shown if it is empty.
+	Title string
+	// Description is shown in the event details, optional
+	Description string
+}
+
+// UID returns the identifier of the event. It depends only on the date and
+// the folded name, not the title, so that importing a calendar again
+// updates the events instead of duplicating them, in any script.
+func (e Event) UID() string {
+	sum := sha1.Sum([]byte(names.Fold(e.Name)))
+	return fmt.Sprintf("%s-%s@namedays", e.Date, hex.EncodeToString(sum[:8]))
+}
+
+// Calendar is the settings of a written calendar
+type Calendar struct {
+	// Name is shown by calendar apps as the calendar title
+	Name string
+	// Reminder adds an alarm this long before the start of the nameday,
+	// e.g. 15h for 9:00 the day before. Negative values remind on the day
+	// itself and zero adds no alarm.
+	Reminder time.Duration
+	// Stamp is the DTSTAMP of the events, usually the current time
+	Stamp time.Time
+	// Year, if set, dates every event once in this year instead of
+	// repeating it yearly, for namedays resolved into the dates of a year
+	// such as the movable feasts. Event UIDs include the year so that the
+	// calendars of different years don't replace each other's events.
+	Year int
+}
+
+// EventsFor returns the events of the namedays of every name in wanted,
+// matched through ix. Each date and name appears once even if several
+// wanted names match it.
+func EventsFor(ix *query.Index, wanted []string) []Event {
+	var events []Event
+	seen := make(map[string]bool)
+	for _, name := range wanted {
+		for _, m := range ix.ByName(name) {
+			e := Event{Date: m.Date, Name: m.Name, Description: describe(m)}
+			if seen[e.UID()] {
+				continue
+			}
+			seen[e.UID()] = true
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func describe(m query.Match) string {
+	description := m.Date.Russian()
+	if len(m.Aliases) > 0 {
+		description += "\nТакже: " + strings.Join(m.Aliases, ", ")
+	}
+	for _, saint := range m.Saints {
+		description += "\nПамять: " + strings.TrimSpace(saint.String()+" "+saint.URL)
+	}
+	if sources := m.SourceNames(); len(sources) > 0 {
+		description += "\nИсточники: " + strings.Join(sources, ", ")
+	}
+	return description
+}
+
+// Write writes events as a VCALENDAR
+func (c Calendar) Write(w io.Writer, events []Event) error {
+	lw := &lineWriter{w: w}
+
+	lw.line("BEGIN:VCALENDAR")
+	lw.line("VERSION:2.0")
+	lw.line("PRODID:-//kvloginov//namedays//RU")
+	lw.line("CALSCALE:GREGORIAN")
+	lw.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		lw.line("X-WR-CALNAME:" + escape(c.Name))
+	}
+
+	stamp := c.Stamp.UTC().Format("20060102T150405Z")
+	for _, e := range events {
+		uid := e.UID()
+		start := time.Date(baseYear, e.Date.Month(), e.Date.Day(), 0, 0, 0, 0, time.UTC)
+		if c.Year != 0 {
+			var err error
+			if start, err = e.Date.In(c.Year, time.UTC); err != nil {
+				return err
+			}
+			uid = fmt.Sprintf("%d%s", c.Year, uid)
+		}
+		title := e.Title
+		if title == "" {
+			title = e.Name
+		}
+		summary := "Именины: " + title
+
+		lw.line("BEGIN:VEVENT")
+		lw.line("UID:" + uid)
+		lw.line("DTSTAMP:" + stamp)
+		lw.line("DTSTART;VALUE=DATE:" + start.Format("20060102"))
+		lw.line("DTEND;VALUE=DATE:" + start.AddDate(0, 0, 1).Format("20060102"))
+		if c.Year == 0 {
+			lw.line("RRULE:" + recurrence(e.Date))
+		}
+		lw.line("SUMMARY:" + escape(summary))
+		if e.Description != "" {
+			lw.line("DESCRIPTION:" + escape(e.Description))
+		}
+		lw.line("TRANSP:TRANSPARENT")
+		if c.Reminder != 0 {
+			lw.line("BEGIN:VALARM")
+			lw.line("ACTION:DISPLAY")
+			lw.line("DESCRIPTION:" + escape(summary))
+			lw.line("TRIGGER:" + trigger(c.Reminder))
+			lw.line("END:VALARM")
+		}
+		lw.line("END:VEVENT")
+	}
+
+	lw.line("END:VCALENDAR")
+	return lw.err
+}
+
+// recurrence returns the yearly RRULE of a date. Feb 29 namedays move to
+// Feb 28 in non-leap years, as in the year calendar, so they recur on the
+// last day of February.
+func recurrence(date domain.DayMonth) string {
+	if date.Month() == time.February && date.Day() == 29 {
+		return "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1"
+	}
+	return "FREQ=YEARLY"
+}
+
+// trigger formats a reminder as a duration relative to the event start
+func trigger(before time.Duration) string {
+	sign := "-"
+	if before < 0 {
+		sign, before = "", -before
+	}
+
+	minutes := int(before / time.Minute)
+	switch {
+	case minutes%(24*60) == 0:
+		return fmt.Sprintf("%sP%dD", sign, minutes/(24*60))
+	case minutes%60 == 0:
+		return fmt.Sprintf("%sPT%dH", sign, minutes/60)
+	default:
+		return fmt.Sprintf("%sPT%dM", sign, minutes)
+	}
+}
+
+// escape escapes a TEXT value
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// lineWriter writes content lines with CRLF endings, folding them at 75
+// octets without splitting UTF-8 characters. It keeps the first error.
+type lineWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (lw *lineWriter) line(s string) {
+	if lw.err != nil {
+		return
+	}
+
+	var b strings.Builder
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space
+		limit = 74
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+
+	_, lw.err = io.WriteString(lw.w, b.String())
+}
@@ -0,0 +1,187 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/domain/domaintest"
+	"github.com/kvloginov/namedays/internal/names"
+	"github.com/kvloginov/namedays/internal/query"
+)
+
+func TestWrite(t *testing.T) {
+	calendar := Calendar{
+		Name:     "Именины",
+		Reminder: 15 * time.Hour,
+		Stamp:    time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC),
+	}
+	events := []Event{{Date: domaintest.Day(t, time.February, 29), Name: "Кассиан", Description: "29 февраля; вчера, завтра"}}
+
+	var b strings.Builder
+	if err := calendar.Write(&b, events); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//kvloginov//namedays//RU",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Именины",
+		"BEGIN:VEVENT",
+		"UID:" + events[0].UID(),
+		"DTSTAMP:20261018T120000Z",
+		"DTSTART;VALUE=DATE:20000229",
+		"DTEND;VALUE=DATE:20000301",
+		"RRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1",
+		"SUMMARY:Именины: Кассиан",
+		`DESCRIPTION:29 февраля\; вчера\, завтра`,
+		"TRANSP:TRANSPARENT",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"DESCRIPTION:Именины: Кассиан",
+		"TRIGGER:-PT15H",
+		"END:VALARM",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	if b.String() != expected {
+		t.Errorf("Unexpected calendar:\n%s", b.String())
+	}
+}
+
+func TestWriteYear(t *testing.T) {
+	calendar := Calendar{Stamp: time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC), Year: 2027}
+	events := []Event{{Date: domaintest.Day(t, time.May, 2), Name: "Фома"}}
+
+	var b strings.Builder
+	if err := calendar.Write(&b, events); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//kvloginov//namedays//RU",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		"UID:2027" + events[0].UID(),
+		"DTSTAMP:20261018T120000Z",
+		"DTSTART;VALUE=DATE:20270502",
+		"DTEND;VALUE=DATE:20270503",
+		"SUMMARY:Именины: Фома",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	if b.String() != expected {
+		t.Errorf("Unexpected calendar:\n%s", b.String())
+	}
+
+	leap := []Event{{Date: domaintest.Day(t, time.February, 29), Name: "Кассиан"}}
+	if err := calendar.Write(&strings.Builder{}, leap); err == nil {
+		t.Error("Expected an error for Feb 29 of a non-leap year")
+	}
+}
+
+func TestLineFolding(t *testing.T) {
+	var b strings.Builder
+	lw := &lineWriter{w: &b}
+	long := "DESCRIPTION:" + strings.Repeat("Именины ", 20)
+	lw.line(long)
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("Expected a folded line, got %q", b.String())
+	}
+
+	var unfolded strings.Builder
+	for i, line := range lines {
+		if len(line) > 75 {
+			t.Errorf("Line %d is %d octets long", i, len(line))
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("Line %d splits a character: %q", i, line)
+		}
+		if i > 0 {
+			line = strings.TrimPrefix(line, " ")
+		}
+		unfolded.WriteString(line)
+	}
+	if unfolded.String() != long {
+		t.Errorf("Unfolding gives %q", unfolded.String())
+	}
+}
+
+func TestEventsFor(t *testing.T) {
+	namedays := domain.NamedaysDataList{
+		{Date: domaintest.Day(t, time.January, 24), Names: []string{"Ксения"}},
+		{
+			Date:  domaintest.Day(t, time.March, 1),
+			Names: []string{"Иван"},
+			Meta: map[string]domain.NameMeta{"Иван": {Saints: []domain.Saint{
+				{Title: "прп. Иоанн Кассиан Римлянин", Type: domain.Venerable, URL: "https://example.com/ioann/"},
+				{Type: domain.Martyr},
+			}}},
+		},
+		{Date: domaintest.Day(t, time.June, 6), Names: []string{"Ксения"}},
+	}
+	dict := &names.Dictionary{Version: names.DictionaryVersion, Groups: []names.Group{{Canonical: "Иван", Variants: []string{"Иоанн"}}}}
+	ix := query.New(namedays, names.NewNormalizer(dict))
+
+	events := EventsFor(ix, []string{"ксения", "Иоанн", "Ксения"})
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %+v", events)
+	}
+
+	// UIDs are stable across spellings and distinct across dates
+	if events[0].UID() != (Event{Date: domaintest.Day(t, time.January, 24), Name: "КСЕНИЯ"}).UID() {
+		t.Error("UID depends on the case of the name")
+	}
+	if events[0].UID() == events[1].UID() {
+		t.Error("Different dates share a UID")
+	}
+
+	// A title in another script shows up in the event but keeps the UID
+	latin := events[0]
+	latin.Title = "Kseniya"
+	if latin.UID() != events[0].UID() {
+		t.Error("UID depends on the title")
+	}
+	var b strings.Builder
+	if err := (Calendar{}).Write(&b, []Event{latin}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "SUMMARY:Именины: Kseniya\r\n") {
+		t.Errorf("Expected the title in the summary, got:\n%s", b.String())
+	}
+
+	expected := "1 марта\nПамять: прп. Иоанн Кассиан Римлянин https://example.com/ioann/\nПамять: мученик"
+	if events[2].Description != expected {
+		t.Errorf("Expected description %q, got %q", expected, events[2].Description)
+	}
+}
+
+func TestTrigger(t *testing.T) {
+	tests := map[time.Duration]string{
+		15 * time.Hour:    "-PT15H",
+		24 * time.Hour:    "-P1D",
+		-9 * time.Hour:    "PT9H",
+		90 * time.Minute:  "-PT90M",
+		-30 * time.Minute: "PT30M",
+	}
+	for d, expected := range tests {
+		if got := trigger(d); got != expected {
+			t.Errorf("trigger(%s) = %s, expected %s", d, got, expected)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package names
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+// DictionaryVersion is the dictionary format version this package reads
+const DictionaryVersion = 1
+
+// DictionaryFile is the name of the variant dictionary in the data directory
+const DictionaryFile = "name_variants.json"
+
+// Dictionary maps spelling variants of a name to one canonical form, e.g.
+// the church-Slavonic "Иоанн" to the civil "Иван"
+type Dictionary struct {
+	Version int     `json:"version"`
+	Groups  []Group `json:"groups"`
+	// Diminutives are the everyday short forms of full names
+	Diminutives []Diminutive `json:"diminutives,omitempty"`
+}
+
+// Group is a canonical name together with its known variants
+type Group struct {
+	Canonical string   `json:"canonical"`
+	Variants  []string `json:"variants"`
+	// Gender overrides the gender guessed from the ending of the name,
+	// for names like Никита
+	Gender domain.Gender `json:"gender,omitempty"`
+}
+
+// LoadDictionary reads a dictionary file
+func LoadDictionary(path string) (*Dictionary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading name dictionary %s: %w", path, err)
+	}
+
+	return ParseDictionary(data)
+}
+
+// ParseDictionary decodes a dictionary and checks that no variant belongs
+// to two different canonical names, and that no short form is a full name
+func ParseDictionary(data []byte) (*Dictionary, error) {
+	var d Dictionary
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("error parsing name dictionary: %w", err)
+	}
+
+	if d.Version != DictionaryVersion {
+		return nil, fmt.Errorf("unsupported name dictionary version %d, expected %d", d.Version, DictionaryVersion)
+	}
+
+	owners := make(map[string]string)
+	for _, g := range d.Groups {
+		if g.Gender != domain.GenderUnknown && g.Gender != domain.Male && g.Gender != domain.Female {
+			return nil, fmt.Errorf("name %s has unknown gender %q", g.Canonical, g.Gender)
+		}
+		for _, name := range append([]string{g.Canonical}, g.Variants...) {
+			key := Fold(name)
+			if owner, ok := owners[key]; ok && owner != g.Canonical {
+				return nil, fmt.Errorf("name %s belongs to both %s and %s", name, owner, g.Canonical)
+			}
+			owners[key] = g.Canonical
+		}
+	}
+
+	for _, dim := range d.Diminutives {
+		if strings.TrimSpace(dim.Full) == "" || len(dim.Short) == 0 {
+			return nil, fmt.Errorf("diminutive needs a full name and short forms: %+v", dim)
+		}
+		for _, short := range dim.Short {
+			if owner, ok := owners[Fold(short)]; ok {
+				return nil, fmt.Errorf("short form %s of %s is a spelling of %s", short, dim.Full, owner)
+			}
+		}
+	}
+
+	return &d, nil
+}
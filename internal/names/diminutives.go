@@ -0,0 +1,67 @@
+package names
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+// Diminutive is a full name with its everyday short forms, e.g. Александр
+// with Саша, Саня and Шура. A short form may belong to several full names:
+// Саша is Александра too.
+type Diminutive struct {
+	Full  string   `json:"full"`
+	Short []string `json:"short"`
+}
+
+// Candidate is a full name a short form may stand for
+type Candidate struct {
+	Name   string        `json:"name"`
+	Gender domain.Gender `json:"gender,omitempty"`
+}
+
+// Expand returns the full names short stands for according to the
+// dictionary, men's names first, then women's, then the ones of unknown
+// gender. It returns nil if short isn't a known short form, and a nil
+// normalizer knows none.
+func (n *Normalizer) Expand(short string) []Candidate {
+	if n == nil {
+		return nil
+	}
+
+	var candidates []Candidate
+	for _, full := range n.diminutives[Fold(short)] {
+		candidates = append(candidates, Candidate{Name: full, Gender: n.Gender(full)})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return genderOrder(candidates[i].Gender) < genderOrder(candidates[j].Gender)
+	})
+	return candidates
+}
+
+// ShortForms returns every short form of the dictionary as it spells
+// them, sorted
+func (n *Normalizer) ShortForms() []string {
+	if n == nil {
+		return nil
+	}
+
+	forms := make([]string, 0, len(n.shortForms))
+	for _, short := range n.shortForms {
+		forms = append(forms, short)
+	}
+	slices.Sort(forms)
+	return forms
+}
+
+func genderOrder(g domain.Gender) int {
+	switch g {
+	case domain.Male:
+		return 0
+	case domain.Female:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,59 @@
+package names
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+func TestExpand(t *testing.T) {
+	dict, err := ParseDictionary([]byte(`{
+		"version": 1,
+		"groups": [{"canonical": "Алексей", "variants": ["Алексий"]}],
+		"diminutives": [
+			{"full": "Александра", "short": ["Саша", "Шура"]},
+			{"full": "Александр", "short": ["Саша", "Саня", "Шура"]},
+			{"full": "Алексей", "short": ["Лёша"]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	n := NewNormalizer(dict)
+
+	tests := []struct {
+		short    string
+		expected []Candidate
+	}{
+		// Men's names come first whatever the order of the dictionary
+		{"саша", []Candidate{{"Александр", domain.Male}, {"Александра", domain.Female}}},
+		{"Леша", []Candidate{{"Алексей", domain.Male}}},
+		{"Александр", nil},
+	}
+	for _, tt := range tests {
+		if got := n.Expand(tt.short); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Expand(%q) = %v, expected %v", tt.short, got, tt.expected)
+		}
+	}
+
+	if forms := n.ShortForms(); !reflect.DeepEqual(forms, []string{"Лёша", "Саня", "Саша", "Шура"}) {
+		t.Errorf("Unexpected short forms %v", forms)
+	}
+
+	var nilNormalizer *Normalizer
+	if got := nilNormalizer.Expand("Саша"); got != nil {
+		t.Errorf("nil Expand() = %v, expected nil", got)
+	}
+}
+
+func TestParseDictionaryRejectsShortFormOfFullName(t *testing.T) {
+	_, err := ParseDictionary([]byte(`{
+		"version": 1,
+		"groups": [{"canonical": "Иван", "variants": ["Ян"]}],
+		"diminutives": [{"full": "Иван", "short": ["Ваня", "Ян"]}]
+	}`))
+	if err == nil {
+		t.Error("Expected an error for a short form that is a spelling of a full name")
+	}
+}
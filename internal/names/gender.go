@@ -0,0 +1,93 @@
+package names
+
+import (
+	"strings"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+// maleOnVowel lists male names ending in а or я, which otherwise mark a
+// female name
+var maleOnVowel = map[string]bool{
+	"агриппа": true, "азария": true, "акила": true, "аникита": true, "анания": true,
+	"антипа": true, "арефа": true, "ахила": true, "ахия": true, "бидзина": true,
+	"вавила": true, "варнава": true, "варсава": true, "езекия": true, "захария": true,
+	"зосима": true, "иеремия": true, "илия": true, "илья": true, "иона": true,
+	"иосия": true, "исаия": true, "исайя": true, "иуда": true, "кифа": true,
+	"клеопа": true, "кузьма": true, "кукша": true, "лука": true, "малахия": true,
+	"мина": true, "никита": true, "никола": true, "осия": true, "памва": true,
+	"сава": true, "савва": true, "сила": true, "софония": true, "фока": true,
+	"фома": true, "шалва": true,
+}
+
+// femaleOnSoftSign lists female names ending in ь, which otherwise marks a
+// male name
+var femaleOnSoftSign = map[string]bool{
+	"есфирь": true, "любовь": true, "нинель": true, "руфь": true, "эсфирь": true,
+	"юдифь": true,
+}
+
+// GuessGender guesses the gender of a Russian name from its ending: а and
+// я are female, consonants, й and ь are male, with the exceptions of
+// church names like Никита or Любовь. Other endings are unknown.
+func GuessGender(name string) domain.Gender {
+	folded := Fold(name)
+	if folded == "" || strings.ContainsAny(folded, " .?()") {
+		return domain.GenderUnknown
+	}
+
+	runes := []rune(folded)
+	switch last := runes[len(runes)-1]; {
+	case last == 'а' || last == 'я':
+		if maleOnVowel[folded] {
+			return domain.Male
+		}
+		return domain.Female
+	case last == 'ь':
+		if femaleOnSoftSign[folded] {
+			return domain.Female
+		}
+		return domain.Male
+	case last == 'й' || strings.ContainsRune("бвгджзклмнпрстфхцчшщ", last):
+		return domain.Male
+	default:
+		return domain.GenderUnknown
+	}
+}
+
+// LearnGender records the gender a source gave a spelling, so that the
+// same name from a source without genders gets it too. The first gender
+// learned for a name wins.
+func (n *Normalizer) LearnGender(name string, g domain.Gender) {
+	key := n.Key(name)
+	if _, ok := n.learned[key]; !ok && g != domain.GenderUnknown {
+		n.learned[key] = g
+	}
+}
+
+// Gender returns the gender of name from the dictionary, else from what
+// the sources said about it, else guessed from its ending. A nil
+// normalizer only guesses.
+func (n *Normalizer) Gender(name string) domain.Gender {
+	if n == nil {
+		return GuessGender(name)
+	}
+
+	key := n.Key(name)
+	if g, ok := n.genders[key]; ok {
+		return g
+	}
+	if g, ok := n.learned[key]; ok {
+		return g
+	}
+	return GuessGender(name)
+}
+
+// GenderIn returns the gender of a name on a date: the one recorded in the
+// data if any, else Gender(name)
+func (n *Normalizer) GenderIn(day domain.NamedaysData, name string) domain.Gender {
+	if g := day.GenderOf(name); g != domain.GenderUnknown {
+		return g
+	}
+	return n.Gender(name)
+}
@@ -0,0 +1,87 @@
+package names
+
+import (
+	"testing"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+func TestGuessGender(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected domain.Gender
+	}{
+		{"Мария", domain.Female},
+		{"Анна", domain.Female},
+		{"Фёкла", domain.Female},
+		{"Любовь", domain.Female},
+		{"Есфирь", domain.Female},
+		{"Юдифь", domain.Female},
+		{"Иван", domain.Male},
+		{"Георгий", domain.Male},
+		{"Игорь", domain.Male},
+		{"Никита", domain.Male},
+		{"Илья", domain.Male},
+		{"Фото", domain.GenderUnknown},
+		{"Герман?", domain.GenderUnknown},
+		{"Успение Пресвятой Богородицы", domain.GenderUnknown},
+		{"", domain.GenderUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := GuessGender(tt.name); got != tt.expected {
+			t.Errorf("GuessGender(%q) = %q, expected %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestNormalizerGender(t *testing.T) {
+	dict, err := ParseDictionary([]byte(`{
+		"version": 1,
+		"groups": [
+			{"canonical": "Иов", "variants": ["Иова"], "gender": "male"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	n := NewNormalizer(dict)
+	n.LearnGender("Фото", domain.Male)
+	n.LearnGender("Фото", domain.Female)
+	n.LearnGender("Иова", domain.Female)
+
+	tests := []struct {
+		name     string
+		expected domain.Gender
+	}{
+		// The dictionary wins over the sources and the ending
+		{"Иова", domain.Male},
+		// The first gender a source gave wins
+		{"фото", domain.Male},
+		{"Анна", domain.Female},
+	}
+	for _, tt := range tests {
+		if got := n.Gender(tt.name); got != tt.expected {
+			t.Errorf("Gender(%q) = %q, expected %q", tt.name, got, tt.expected)
+		}
+	}
+
+	day := domain.NamedaysData{Names: []string{"Анна"}}
+	day.SetGender("Анна", domain.Male)
+	if got := n.GenderIn(day, "Анна"); got != domain.Male {
+		t.Errorf("GenderIn() = %q, expected the recorded %q", got, domain.Male)
+	}
+
+	var nilNormalizer *Normalizer
+	if got := nilNormalizer.Gender("Никита"); got != domain.Male {
+		t.Errorf("nil Gender(Никита) = %q, expected %q", got, domain.Male)
+	}
+}
+
+func TestParseDictionaryRejectsUnknownGender(t *testing.T) {
+	_, err := ParseDictionary([]byte(`{"version": 1, "groups": [{"canonical": "Иван", "variants": [], "gender": "m"}]}`))
+	if err == nil {
+		t.Error("Expected an error for an unknown gender")
+	}
+}
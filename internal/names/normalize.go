@@ -0,0 +1,273 @@
+package names
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+// Fold returns the comparison key of a name: trimmed, lower case and with
+// ё replaced by е
+func Fold(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.ReplaceAll(name, "ё", "е")
+}
+
+// Cluster is a canonical name with the spellings that were folded into it
+type Cluster struct {
+	Canonical string
+	// Aliases are the original spellings other than Canonical
+	Aliases []string
+}
+
+// Normalizer groups spelling variants of names under canonical forms
+type Normalizer struct {
+	canonical map[string]string
+	// frequency counts every spelling seen across the dataset, it breaks
+	// ties when choosing the canonical spelling of a cluster
+	frequency map[string]int
+	// genders holds the genders of the dictionary and the ones learned from
+	// the sources, by comparison key
+	genders map[string]domain.Gender
+	learned map[string]domain.Gender
+	// diminutives holds the full names of every folded short form, and
+	// shortForms its spelling in the dictionary
+	diminutives map[string][]string
+	shortForms  map[string]string
+}
+
+// NewNormalizer creates a normalizer backed by d. A nil dictionary only
+// folds ё and clusters typos.
+func NewNormalizer(d *Dictionary) *Normalizer {
+	n := &Normalizer{
+		canonical: make(map[string]string),
+		frequency: make(map[string]int),
+		genders:   make(map[string]domain.Gender),
+		learned:   make(map[string]domain.Gender),
+
+		diminutives: make(map[string][]string),
+		shortForms:  make(map[string]string),
+	}
+
+	if d != nil {
+		for _, g := range d.Groups {
+			n.canonical[Fold(g.Canonical)] = g.Canonical
+			for _, v := range g.Variants {
+				n.canonical[Fold(v)] = g.Canonical
+			}
+			if g.Gender != domain.GenderUnknown {
+				n.genders[Fold(g.Canonical)] = g.Gender
+			}
+		}
+		for _, dim := range d.Diminutives {
+			for _, short := range dim.Short {
+				key := Fold(short)
+				if _, ok := n.shortForms[key]; !ok {
+					n.shortForms[key] = short
+				}
+				if !slices.Contains(n.diminutives[key], dim.Full) {
+					n.diminutives[key] = append(n.diminutives[key], dim.Full)
+				}
+			}
+		}
+	}
+
+	return n
+}
+
+// Learn counts spellings across the whole dataset, so that a rare typo
+// doesn't win over the common spelling on a date where both appear once
+func (n *Normalizer) Learn(names []string) {
+	for _, name := range names {
+		n.frequency[name]++
+	}
+}
+
+// Canonical returns the dictionary form of name, or name itself if the
+// dictionary doesn't know it
+func (n *Normalizer) Canonical(name string) string {
+	if canonical, ok := n.canonical[Fold(name)]; ok {
+		return canonical
+	}
+	return strings.TrimSpace(name)
+}
+
+// Same reports whether two spellings are variants of the same name
+// according to the dictionary and ё folding. Typos aren't considered.
+func (n *Normalizer) Same(a, b string) bool {
+	return n.Key(a) == n.Key(b)
+}
+
+// Key returns the comparison key of a name: the folded dictionary form, or
+// the folded name itself if the dictionary doesn't know it. A nil
+// normalizer only folds.
+func (n *Normalizer) Key(name string) string {
+	if n == nil {
+		return Fold(name)
+	}
+	if canonical, ok := n.canonical[Fold(name)]; ok {
+		return Fold(canonical)
+	}
+	return Fold(name)
+}
+
+// Group clusters spellings that name the same person. counts holds how many
+// times each spelling was seen, e.g. by how many sources. A cluster is
+// named by its dictionary form or else by its most frequent spelling.
+func (n *Normalizer) Group(counts map[string]int) []Cluster {
+	type group struct {
+		key       string
+		canonical string
+		spellings map[string]int
+	}
+
+	groups := make(map[string]*group)
+	for spelling, count := range counts {
+		key := n.Key(spelling)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{key: key, spellings: make(map[string]int)}
+			if canonical, known := n.canonical[Fold(spelling)]; known {
+				g.canonical = canonical
+			}
+			groups[key] = g
+		}
+		g.spellings[spelling] += count
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Fold unknown spellings that look like typos of another group into it
+	for _, key := range keys {
+		g := groups[key]
+		if g.canonical != "" {
+			continue
+		}
+		for _, other := range keys {
+			target, ok := groups[other]
+			if !ok || other == key || !n.isTypo(key, other) || total(target.spellings) < total(g.spellings) {
+				continue
+			}
+			for spelling, count := range g.spellings {
+				target.spellings[spelling] += count
+			}
+			delete(groups, key)
+			break
+		}
+	}
+
+	var clusters []Cluster
+	for _, key := range keys {
+		g, ok := groups[key]
+		if !ok {
+			continue
+		}
+
+		canonical := g.canonical
+		if canonical == "" {
+			canonical = n.mostFrequent(g.spellings)
+		}
+
+		var aliases []string
+		for spelling := range g.spellings {
+			if spelling != canonical {
+				aliases = append(aliases, spelling)
+			}
+		}
+		sort.Strings(aliases)
+
+		clusters = append(clusters, Cluster{Canonical: canonical, Aliases: aliases})
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].Canonical < clusters[j].Canonical
+	})
+
+	return clusters
+}
+
+// isTypo reports whether two folded names differ by a likely typo: a single
+// dropped or doubled letter inside the name, as in Полиеввкт/Полиевкт.
+// Substitutions are never merged because they usually are different saints
+// commemorated together (Фотина/Фотида), and neither are short names or
+// different first and last letters (Мелевсипп/Елевсипп, Александр/Александра).
+func (n *Normalizer) isTypo(a, b string) bool {
+	la, lb := utf8.RuneCountInString(a), utf8.RuneCountInString(b)
+	if la < 6 || lb < 6 || (la-lb != 1 && lb-la != 1) {
+		return false
+	}
+
+	firstA, _ := utf8.DecodeRuneInString(a)
+	firstB, _ := utf8.DecodeRuneInString(b)
+	lastA, _ := utf8.DecodeLastRuneInString(a)
+	lastB, _ := utf8.DecodeLastRuneInString(b)
+	if firstA != firstB || lastA != lastB {
+		return false
+	}
+
+	return Distance(a, b) == 1
+}
+
+// Distance returns the Levenshtein edit distance between two strings,
+// counted in runes
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func total(spellings map[string]int) int {
+	sum := 0
+	for _, count := range spellings {
+		sum += count
+	}
+	return sum
+}
+
+// mostFrequent returns the spelling seen most often. Ties go to the
+// spelling that is more common across the dataset, then to the one with ё,
+// then to the alphabetically first one.
+func (n *Normalizer) mostFrequent(spellings map[string]int) string {
+	best, bestCount := "", -1
+	for spelling, count := range spellings {
+		if count > bestCount || (count == bestCount && n.preferSpelling(spelling, best)) {
+			best, bestCount = spelling, count
+		}
+	}
+	return best
+}
+
+func (n *Normalizer) preferSpelling(a, b string) bool {
+	if fa, fb := n.frequency[a], n.frequency[b]; fa != fb {
+		return fa > fb
+	}
+	if hasYo, bHasYo := strings.ContainsAny(a, "ёЁ"), strings.ContainsAny(b, "ёЁ"); hasYo != bHasYo {
+		return hasYo
+	}
+	return a < b
+}
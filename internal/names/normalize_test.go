@@ -0,0 +1,75 @@
+package names
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizerGroup(t *testing.T) {
+	dict, err := ParseDictionary([]byte(`{
+		"version": 1,
+		"groups": [
+			{"canonical": "Иван", "variants": ["Иоанн"]},
+			{"canonical": "Георгий", "variants": ["Юрий", "Егор"]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	n := NewNormalizer(dict)
+	n.Learn([]string{"Полиевкт", "Полиевкт", "Полиеввкт"})
+
+	clusters := n.Group(map[string]int{
+		"Иоанн":     2,
+		"Иван":      1,
+		"Юрий":      1,
+		"Пётр":      1,
+		"Петр":      1,
+		"Полиеввкт": 1,
+		"Полиевкт":  1,
+		"Мария":     1,
+		"Марина":    1,
+	})
+
+	expected := []Cluster{
+		{Canonical: "Георгий", Aliases: []string{"Юрий"}},
+		{Canonical: "Иван", Aliases: []string{"Иоанн"}},
+		{Canonical: "Марина"},
+		{Canonical: "Мария"},
+		{Canonical: "Полиевкт", Aliases: []string{"Полиеввкт"}},
+		{Canonical: "Пётр", Aliases: []string{"Петр"}},
+	}
+
+	if !reflect.DeepEqual(clusters, expected) {
+		t.Errorf("Expected:\n%+v\nGot:\n%+v", expected, clusters)
+	}
+}
+
+func TestParseDictionaryRejectsConflicts(t *testing.T) {
+	_, err := ParseDictionary([]byte(`{
+		"version": 1,
+		"groups": [
+			{"canonical": "Иван", "variants": ["Иоанн"]},
+			{"canonical": "Ян", "variants": ["Иоанн"]}
+		]
+	}`))
+	if err == nil {
+		t.Error("Expected an error for a variant in two groups")
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := map[[2]string]int{
+		{"", ""}:         0,
+		{"Петр", "Пётр"}: 1,
+		{"Полиевкт", "Полиеввкт"}: 1,
+		{"Иоанн", "Иван"}:         2,
+	}
+
+	for pair, expected := range tests {
+		if got := Distance(pair[0], pair[1]); got != expected {
+			t.Errorf("Distance(%s, %s): expected %d, got %d", pair[0], pair[1], expected, got)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package query
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/names"
+	"github.com/kvloginov/namedays/internal/translit"
+)
+
+// Match is a name celebrated on a date
+type Match struct {
+	Date domain.DayMonth `json:"date"`
+	// Name is the spelling the dataset lists, its canonical form in merged
+	// data
+	Name string `json:"name"`
+	// Aliases are the other spellings of Name the sources used on this date
+	Aliases []string `json:"aliases,omitempty"`
+	// Sources are the sources that reported Name on this date, in merged
+	// data only
+	Sources []domain.Attribution `json:"sources,omitempty"`
+	// Gender is the gender recorded in the data, or else the one inferred
+	// from the names dictionary and the ending of the name
+	Gender domain.Gender `json:"gender,omitempty"`
+	// Saints are the saints Name is given after on this date, where the
+	// sources tell
+	Saints []domain.Saint `json:"saints,omitempty"`
+	// Diminutive is the short form the match was looked up by, e.g. Саша
+	// for Александр, empty if Name was asked for directly
+	Diminutive string `json:"diminutive,omitempty"`
+}
+
+// SourceNames returns the distinct sources of the match in the order they
+// were recorded
+func (m Match) SourceNames() []string {
+	var result []string
+	for _, a := range m.Sources {
+		if !slices.Contains(result, a.Source) {
+			result = append(result, a.Source)
+		}
+	}
+	return result
+}
+
+// Index answers name and date lookups over a namedays dataset
+type Index struct {
+	normalizer *names.Normalizer
+	// matches holds every name of the dataset in date order
+	matches []Match
+	// byKey holds the positions in matches of every comparison key, for
+	// the canonical name as well as its aliases
+	byKey map[string][]int
+	// latin holds the Latin keys of every spelling, and byLatin the
+	// comparison keys every Latin key stands for
+	latin   map[string][]string
+	byLatin map[string][]string
+	// shortForms holds the short forms of the dictionary by Latin key
+	shortForms map[string]string
+}
+
+// New indexes namedays. Names are compared by n, so that case, ё and the
+// variants of its dictionary don't matter; a nil n only folds case and ё.
+func New(namedays domain.NamedaysDataList, n *names.Normalizer) *Index {
+	sorted := slices.Clone(namedays)
+	slices.SortStableFunc(sorted, func(a, b domain.NamedaysData) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	var matches []Match
+	for _, day := range sorted {
+		for _, name := range day.Names {
+			matches = append(matches, Match{
+				Date:    day.Date,
+				Name:    name,
+				Aliases: day.Aliases[name],
+				Sources: day.Sources[name],
+				Gender:  n.GenderIn(day, name),
+				Saints:  day.SaintsOf(name),
+			})
+		}
+	}
+
+	return newIndex(matches, n)
+}
+
+// newIndex indexes matches that are in date order
+func newIndex(matches []Match, n *names.Normalizer) *Index {
+	ix := &Index{
+		normalizer: n,
+		matches:    matches,
+		byKey:      make(map[string][]int),
+		latin:      make(map[string][]string),
+		byLatin:    make(map[string][]string),
+		shortForms: make(map[string]string),
+	}
+	for _, short := range n.ShortForms() {
+		for _, latin := range translit.Keys(short) {
+			if _, ok := ix.shortForms[latin]; !ok {
+				ix.shortForms[latin] = short
+			}
+		}
+	}
+	for pos, m := range matches {
+		for _, spelling := range append([]string{m.Name}, m.Aliases...) {
+			key := n.Key(spelling)
+			if positions := ix.byKey[key]; len(positions) == 0 || positions[len(positions)-1] != pos {
+				ix.byKey[key] = append(positions, pos)
+			}
+
+			if _, ok := ix.latin[spelling]; ok {
+				continue
+			}
+			ix.latin[spelling] = translit.Keys(spelling)
+			for _, latin := range ix.latin[spelling] {
+				if !slices.Contains(ix.byLatin[latin], key) {
+					ix.byLatin[latin] = append(ix.byLatin[latin], key)
+				}
+			}
+		}
+	}
+	return ix
+}
+
+// OnlyGender returns an index of the names of gender g only, names of
+// unknown gender are left out
+func (ix *Index) OnlyGender(g domain.Gender) *Index {
+	var matches []Match
+	for _, m := range ix.matches {
+		if m.Gender == g {
+			matches = append(matches, m)
+		}
+	}
+	return newIndex(matches, ix.normalizer)
+}
+
+// ByName returns the dates on which name is celebrated, in date order. A
+// name in Latin script matches the Cyrillic names it transliterates, in any
+// of the schemes of package translit. A short form of the dictionary that
+// isn't a name of the dataset, like Саша, matches the dates of all of its
+// full names, with Diminutive set. The chart of index.html matches names
+// the same way, keep the two in sync.
+func (ix *Index) ByName(name string) []Match {
+	positions := ix.byKey[ix.normalizer.Key(name)]
+	if len(positions) == 0 && translit.IsLatin(name) {
+		positions = ix.positions(ix.byLatin[translit.Key(name)])
+	}
+	if len(positions) > 0 {
+		return ix.at(positions, "")
+	}
+
+	var keys []string
+	for _, c := range ix.Candidates(name) {
+		keys = append(keys, ix.normalizer.Key(c.Name))
+	}
+	return ix.at(ix.positions(keys), strings.TrimSpace(name))
+}
+
+// Candidates returns the full names the short form name stands for that
+// have namedays in the index, men's names first. name may be in Latin
+// script. It returns nil if name isn't a short form of the dictionary.
+func (ix *Index) Candidates(name string) []names.Candidate {
+	short := name
+	if translit.IsLatin(name) {
+		short = ix.shortForms[translit.Key(name)]
+	}
+
+	var result []names.Candidate
+	for _, c := range ix.normalizer.Expand(short) {
+		if len(ix.byKey[ix.normalizer.Key(c.Name)]) > 0 {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// positions returns the positions of all keys in date order
+func (ix *Index) positions(keys []string) []int {
+	var positions []int
+	for _, key := range keys {
+		positions = append(positions, ix.byKey[key]...)
+	}
+	slices.Sort(positions)
+	return slices.Compact(positions)
+}
+
+// at returns the matches at positions, looked up by the short form
+// diminutive if not empty
+func (ix *Index) at(positions []int, diminutive string) []Match {
+	var result []Match
+	for _, pos := range positions {
+		m := ix.matches[pos]
+		m.Diminutive = diminutive
+		result = append(result, m)
+	}
+	return result
+}
+
+// ByDate returns the names celebrated on date
+func (ix *Index) ByDate(date domain.DayMonth) []Match {
+	start, _ := slices.BinarySearchFunc(ix.matches, date, func(m Match, d domain.DayMonth) int {
+		return m.Date.Compare(d)
+	})
+
+	var result []Match
+	for _, m := range ix.matches[start:] {
+		if m.Date != date {
+			break
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// Next returns the first nameday of name on or after the day of from,
+// together with the day it falls on in from's location. Namedays on Feb 29
+// are celebrated on Feb 28 in non-leap years, as in the year calendar.
+func (ix *Index) Next(name string, from time.Time) (Match, time.Time, bool) {
+	matches := ix.ByName(name)
+	if len(matches) == 0 {
+		return Match{}, time.Time{}, false
+	}
+
+	today := domain.NewDayMonth(from)
+	for year := from.Year(); year <= from.Year()+1; year++ {
+		for _, m := range matches {
+			date := m.Date
+			if !date.ExistsIn(year) {
+				date = date.Prev()
+			}
+			if year == from.Year() && date.Before(today) {
+				continue
+			}
+
+			on, err := date.In(year, from.Location())
+			if err != nil {
+				continue
+			}
+			return m, on, true
+		}
+	}
+
+	return Match{}, time.Time{}, false
+}
+
+// NameDates is a name with every date it is celebrated on
+type NameDates struct {
+	Name   string            `json:"name"`
+	Gender domain.Gender     `json:"gender,omitempty"`
+	Dates  []domain.DayMonth `json:"dates"`
+}
+
+// Search returns the names whose spelling or alias contains text, ignoring
+// case and ё, in alphabetical order. Names matching at the start come
+// first. Text in Latin script is compared with the transliterations of the
+// names. limit caps the number of names if positive.
+func (ix *Index) Search(text string, limit int) []NameDates {
+	needle := names.Fold(text)
+	forms := func(spelling string) []string {
+		return []string{names.Fold(spelling)}
+	}
+	if translit.IsLatin(text) {
+		needle = translit.Key(text)
+		forms = func(spelling string) []string {
+			return ix.latin[spelling]
+		}
+	}
+	if needle == "" {
+		return nil
+	}
+
+	byName := make(map[string]*NameDates)
+	prefix := make(map[string]bool)
+	for _, m := range ix.matches {
+		for _, spelling := range append([]string{m.Name}, m.Aliases...) {
+			for _, form := range forms(spelling) {
+				if !strings.Contains(form, needle) {
+					continue
+				}
+
+				entry, ok := byName[m.Name]
+				if !ok {
+					entry = &NameDates{Name: m.Name, Gender: m.Gender}
+					byName[m.Name] = entry
+				}
+				if !slices.Contains(entry.Dates, m.Date) {
+					entry.Dates = append(entry.Dates, m.Date)
+				}
+				if strings.HasPrefix(form, needle) {
+					prefix[m.Name] = true
+				}
+			}
+		}
+	}
+
+	result := make([]NameDates, 0, len(byName))
+	for _, entry := range byName {
+		result = append(result, *entry)
+	}
+	slices.SortFunc(result, func(a, b NameDates) int {
+		if prefix[a.Name] != prefix[b.Name] {
+			if prefix[a.Name] {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(strings.Compare(names.Fold(a.Name), names.Fold(b.Name)), strings.Compare(a.Name, b.Name))
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
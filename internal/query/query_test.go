@@ -0,0 +1,208 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/domain/domaintest"
+	"github.com/kvloginov/namedays/internal/names"
+)
+
+func testIndex(t *testing.T) *Index {
+	namedays := domain.NamedaysDataList{
+		{
+			Date:    domaintest.Day(t, time.March, 1),
+			Names:   []string{"Иван"},
+			Aliases: map[string][]string{"Иван": {"Иоанн"}},
+			Sources: map[string][]domain.Attribution{"Иван": {{Source: "calend", Spelling: "Иван"}, {Source: "pravmir", Spelling: "Иоанн"}}},
+		},
+		{Date: domaintest.Day(t, time.February, 29), Names: []string{"Кассиан"}},
+		{Date: domaintest.Day(t, time.January, 24), Names: []string{"Ксения", "Пётр"}},
+		{Date: domaintest.Day(t, time.June, 6), Names: []string{"Ксения"}},
+	}
+
+	dict := &names.Dictionary{Version: names.DictionaryVersion, Groups: []names.Group{{Canonical: "Иван", Variants: []string{"Иоанн", "Ян"}}}}
+	return New(namedays, names.NewNormalizer(dict))
+}
+
+func TestByName(t *testing.T) {
+	ix := testIndex(t)
+
+	dates := func(matches []Match) []domain.DayMonth {
+		var result []domain.DayMonth
+		for _, m := range matches {
+			result = append(result, m.Date)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		expected []domain.DayMonth
+	}{
+		{"КСЕНИЯ", []domain.DayMonth{domaintest.Day(t, time.January, 24), domaintest.Day(t, time.June, 6)}},
+		{"петр", []domain.DayMonth{domaintest.Day(t, time.January, 24)}},
+		{"Иоанн", []domain.DayMonth{domaintest.Day(t, time.March, 1)}},
+		// A dictionary variant the dataset never used
+		{"Ян", []domain.DayMonth{domaintest.Day(t, time.March, 1)}},
+		// Latin spellings in any transliteration
+		{"Kseniya", []domain.DayMonth{domaintest.Day(t, time.January, 24), domaintest.Day(t, time.June, 6)}},
+		{"Xenia", []domain.DayMonth{domaintest.Day(t, time.January, 24), domaintest.Day(t, time.June, 6)}},
+		{"Pyotr", []domain.DayMonth{domaintest.Day(t, time.January, 24)}},
+		{"Ivan", []domain.DayMonth{domaintest.Day(t, time.March, 1)}},
+		{"Никто", nil},
+	}
+
+	for _, tt := range tests {
+		if got := dates(ix.ByName(tt.name)); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("ByName(%q) = %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+
+	ivan := ix.ByName("иван")[0]
+	if !reflect.DeepEqual(ivan.SourceNames(), []string{"calend", "pravmir"}) {
+		t.Errorf("Unexpected sources: %v", ivan.SourceNames())
+	}
+}
+
+func TestByNameDiminutive(t *testing.T) {
+	namedays := domain.NamedaysDataList{
+		{Date: domaintest.Day(t, time.March, 24), Names: []string{"Александра"}},
+		{Date: domaintest.Day(t, time.September, 12), Names: []string{"Александр"}},
+		{Date: domaintest.Day(t, time.October, 1), Names: []string{"Саша"}},
+	}
+	dict := &names.Dictionary{
+		Version: names.DictionaryVersion,
+		Diminutives: []names.Diminutive{
+			{Full: "Александр", Short: []string{"Саша", "Шура"}},
+			{Full: "Александра", Short: []string{"Саша", "Шура"}},
+			{Full: "Алексей", Short: []string{"Лёша"}},
+		},
+	}
+	ix := New(namedays, names.NewNormalizer(dict))
+
+	matches := ix.ByName("шура")
+	if len(matches) != 2 || matches[0].Name != "Александра" || matches[1].Name != "Александр" || matches[0].Diminutive != "шура" {
+		t.Errorf("Unexpected matches: %+v", matches)
+	}
+	// A short form the dataset lists as a name is that name
+	if matches := ix.ByName("Саша"); len(matches) != 1 || matches[0].Diminutive != "" {
+		t.Errorf("Unexpected matches: %+v", matches)
+	}
+	if matches := ix.ByName("Shura"); len(matches) != 2 || matches[0].Diminutive != "Shura" {
+		t.Errorf("Unexpected matches: %+v", matches)
+	}
+
+	expected := []names.Candidate{{Name: "Александр", Gender: domain.Male}, {Name: "Александра", Gender: domain.Female}}
+	if got := ix.Candidates("Шура"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	// Full names without namedays are left out
+	if got := ix.Candidates("Лёша"); got != nil {
+		t.Errorf("Expected no candidates, got %v", got)
+	}
+}
+
+func TestByDate(t *testing.T) {
+	ix := testIndex(t)
+
+	matches := ix.ByDate(domaintest.Day(t, time.January, 24))
+	if len(matches) != 2 || matches[0].Name != "Ксения" || matches[1].Name != "Пётр" {
+		t.Errorf("Unexpected matches: %+v", matches)
+	}
+	if matches := ix.ByDate(domaintest.Day(t, time.January, 25)); len(matches) != 0 {
+		t.Errorf("Expected no matches, got %+v", matches)
+	}
+}
+
+func TestOnlyGender(t *testing.T) {
+	namedays := domain.NamedaysDataList{
+		{
+			Date:  domaintest.Day(t, time.January, 24),
+			Names: []string{"Ксения", "Пётр", "Фото"},
+			// The data wins over the ending of the name
+			Meta: map[string]domain.NameMeta{"Пётр": {Gender: domain.Female}},
+		},
+		{Date: domaintest.Day(t, time.January, 25), Names: []string{"Никита"}},
+	}
+	ix := New(namedays, nil)
+
+	var women []string
+	for _, m := range ix.OnlyGender(domain.Female).ByDate(domaintest.Day(t, time.January, 24)) {
+		women = append(women, m.Name)
+	}
+	if expected := []string{"Ксения", "Пётр"}; !reflect.DeepEqual(women, expected) {
+		t.Errorf("Expected %v, got %v", expected, women)
+	}
+
+	men := ix.OnlyGender(domain.Male)
+	if matches := men.ByName("Никита"); len(matches) != 1 || matches[0].Gender != domain.Male {
+		t.Errorf("Expected Никита among men, got %+v", matches)
+	}
+	if matches := men.ByName("Ксения"); len(matches) != 0 {
+		t.Errorf("Expected no Ксения among men, got %+v", matches)
+	}
+	// Names of unknown gender are in neither index
+	if matches := ix.ByName("Фото"); len(matches) != 1 || matches[0].Gender != domain.GenderUnknown {
+		t.Errorf("Expected Фото of unknown gender, got %+v", matches)
+	}
+}
+
+func TestNext(t *testing.T) {
+	ix := testIndex(t)
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		expected string
+	}{
+		{"Ксения", time.Date(2026, time.January, 24, 15, 0, 0, 0, time.UTC), "2026-01-24"},
+		{"Ксения", time.Date(2026, time.January, 25, 0, 0, 0, 0, time.UTC), "2026-06-06"},
+		{"Ксения", time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC), "2027-01-24"},
+		// Feb 29 falls on Feb 28 in non-leap years
+		{"Кассиан", time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC), "2026-02-28"},
+		{"Кассиан", time.Date(2028, time.February, 1, 0, 0, 0, 0, time.UTC), "2028-02-29"},
+	}
+
+	for _, tt := range tests {
+		_, on, found := ix.Next(tt.name, tt.from)
+		if !found || on.Format(time.DateOnly) != tt.expected {
+			t.Errorf("Next(%q, %s) = %s, %v, expected %s", tt.name, tt.from, on, found, tt.expected)
+		}
+	}
+
+	if _, _, found := ix.Next("Никто", time.Now()); found {
+		t.Error("Expected no nameday for an unknown name")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ix := testIndex(t)
+
+	results := ix.Search("ИО", 0)
+	if len(results) != 1 || results[0].Name != "Иван" {
+		t.Errorf("Expected Иван by its alias, got %+v", results)
+	}
+
+	// Prefix matches come first
+	results = ix.Search("и", 0)
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Name)
+	}
+	expected := []string{"Иван", "Кассиан", "Ксения"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	results = ix.Search("Kse", 0)
+	if len(results) != 1 || results[0].Name != "Ксения" {
+		t.Errorf("Expected Ксения for a Latin prefix, got %+v", results)
+	}
+
+	if results := ix.Search("ксе", 1); len(results) != 1 || !reflect.DeepEqual(results[0].Dates, []domain.DayMonth{domaintest.Day(t, time.January, 24), domaintest.Day(t, time.June, 6)}) {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+}
@@ -0,0 +1,176 @@
+package roster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/scoring"
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the roster schema version this package writes. Files without
+// a version predate versioning and are read as version 1. Version 2 added
+// the optional gender of people.
+const Version = 2
+
+// Roster is the list of people taking part in the namedays competition
+type Roster struct {
+	Version int              `json:"version" yaml:"version"`
+	People  []scoring.Person `json:"people" yaml:"people"`
+	// IncludeRelatives counts the namedays of parents and grandparents, it
+	// is true unless the file says otherwise
+	IncludeRelatives bool `json:"include_relatives" yaml:"include_relatives"`
+}
+
+// New creates a roster of the current version that includes relatives
+func New(people ...scoring.Person) *Roster {
+	return &Roster{Version: Version, People: people, IncludeRelatives: true}
+}
+
+// Load reads a roster from a YAML or JSON file, chosen by its extension
+func Load(path string) (*Roster, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading roster %s: %w", path, err)
+	}
+
+	r, err := ParseFile(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("error loading roster %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// ParseFile decodes the contents of a roster file as YAML or JSON, chosen by
+// the extension of its name
+func ParseFile(name string, data []byte) (*Roster, error) {
+	format, err := FormatOf(name)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatYAML {
+		return ParseYAML(data)
+	}
+	return ParseJSON(data)
+}
+
+// ParseJSON decodes a JSON roster
+func ParseJSON(data []byte) (*Roster, error) {
+	r := &Roster{IncludeRelatives: true}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(r); err != nil {
+		return nil, err
+	}
+	return r, r.check()
+}
+
+// ParseYAML decodes a YAML roster
+func ParseYAML(data []byte) (*Roster, error) {
+	r := &Roster{IncludeRelatives: true}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(r); err != nil {
+		return nil, err
+	}
+	return r, r.check()
+}
+
+// check rejects versions this package doesn't know and invalid rosters
+func (r *Roster) check() error {
+	if r.Version < 0 || r.Version > Version {
+		return fmt.Errorf("unsupported roster version %d, expected at most %d", r.Version, Version)
+	}
+	return r.Validate()
+}
+
+// Migrate upgrades r to the current schema version and reports whether it
+// had to
+func (r *Roster) Migrate() bool {
+	if r.Version == Version {
+		return false
+	}
+	// Unversioned files have the same fields as version 1, which has those
+	// of version 2 but the optional gender
+	r.Version = Version
+	return true
+}
+
+// Validate checks that every person and relative has a name, and that
+// genders are male or female if given
+func (r *Roster) Validate() error {
+	if len(r.People) == 0 {
+		return fmt.Errorf("roster has no people")
+	}
+
+	for i, p := range r.People {
+		if strings.TrimSpace(p.Name) == "" {
+			return fmt.Errorf("person %d has no name", i+1)
+		}
+		if p.Gender != domain.GenderUnknown && p.Gender != domain.Male && p.Gender != domain.Female {
+			return fmt.Errorf("%s has unknown gender %q (expected male or female)", p.DisplayName(), p.Gender)
+		}
+		for _, relative := range append(append([]string{}, p.Parents...), p.Grandparents...) {
+			if strings.TrimSpace(relative) == "" {
+				return fmt.Errorf("%s has a relative without a name", p.DisplayName())
+			}
+		}
+	}
+	return nil
+}
+
+// Format is a roster file format
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// ParseFormat parses a format name as given on the command line
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatJSON, FormatYAML:
+		return f, nil
+	case "yml":
+		return FormatYAML, nil
+	default:
+		return "", fmt.Errorf("unknown format: %s (expected json or yaml)", s)
+	}
+}
+
+// FormatOf returns the format of a roster file by its extension. Files
+// without one are JSON.
+func FormatOf(name string) (Format, error) {
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".json", "":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown roster file extension %s (expected .json, .yaml or .yml)", ext)
+	}
+}
+
+// Write renders the roster in the given format
+func (r *Roster) Write(w io.Writer, format Format) error {
+	if format == FormatYAML {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r)
+}
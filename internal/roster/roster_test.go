@@ -0,0 +1,98 @@
+package roster
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/scoring"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"team.yaml": "people:\n  - name: Ксения\n    surname_initial: П.\n    parents: [Иван, Мария]\n    grandparents: [Анна]\n    gender: female\n",
+		"team.json": `{"people": [{"name": "Ксения", "surname_initial": "П.", "parents": ["Иван", "Мария"], "grandparents": ["Анна"], "gender": "female"}]}`,
+	}
+
+	expected := &Roster{
+		People:           []scoring.Person{{Name: "Ксения", SurnameInitial: "П.", Parents: []string{"Иван", "Мария"}, Grandparents: []string{"Анна"}, Gender: domain.Female}},
+		IncludeRelatives: true,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := Load(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(r, expected) {
+			t.Errorf("%s: expected %+v, got %+v", name, expected, r)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"no people":      `{"people": []}`,
+		"no name":        `{"people": [{"name": " "}]}`,
+		"empty parent":   `{"people": [{"name": "Анна", "parents": [""]}]}`,
+		"unknown field":  `{"people": [{"name": "Анна", "mother": "Мария"}]}`,
+		"unknown gender": `{"people": [{"name": "Анна", "gender": "f"}]}`,
+	}
+	for name, content := range tests {
+		if _, err := ParseJSON([]byte(content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	r, err := ParseYAML([]byte("include_relatives: false\npeople:\n  - name: Анна\n"))
+	if err != nil || r.IncludeRelatives {
+		t.Errorf("Expected relatives to be excluded, got %+v, %v", r, err)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	// Files written before versioning are read as they are and migrated
+	r, err := ParseJSON([]byte(`{"people": [{"name": "Анна"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Version != 0 || !r.Migrate() || r.Version != Version || r.Migrate() {
+		t.Errorf("Unexpected migration of %+v", r)
+	}
+
+	if _, err := ParseJSON([]byte(`{"version": 99, "people": [{"name": "Анна"}]}`)); err == nil {
+		t.Error("Expected an error for a future version")
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	r := New(
+		scoring.Person{Name: "Ксения", SurnameInitial: "П.", Parents: []string{"Иван", "Мария"}, Grandparents: []string{"Анна"}},
+		scoring.Person{Name: "Ян"},
+	)
+	r.IncludeRelatives = false
+
+	for _, format := range []Format{FormatJSON, FormatYAML} {
+		var b strings.Builder
+		if err := r.Write(&b, format); err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := ParseFile("roster."+string(format), []byte(b.String()))
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", format, err, b.String())
+		}
+		if !reflect.DeepEqual(parsed, r) {
+			t.Errorf("%s: round trip gives %+v", format, parsed)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package roster
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/scoring"
+)
+
+// URLParam is the query parameter index.html keeps the roster in
+const URLParam = "names"
+
+// page is the roster as index.html packs it into the URL: JSON with the
+// field names of the page, UTF-8 and base64 encoded
+type page struct {
+	// Version is missing from links shared before versioning
+	Version          int          `json:"version,omitempty"`
+	People           []pagePerson `json:"people"`
+	IncludeRelatives *bool        `json:"includeRelatives,omitempty"`
+}
+
+type pagePerson struct {
+	Name           string   `json:"name"`
+	SurnameInitial string   `json:"surnameInitial"`
+	Parents        []string `json:"parents"`
+	Grandparents   []string `json:"grandparents"`
+	// Gender is missing from links of version 1 and people without one
+	Gender domain.Gender `json:"gender,omitempty"`
+}
+
+// EncodeURL returns the value of the names URL parameter for r, in the form
+// index.html reads
+func (r *Roster) EncodeURL() (string, error) {
+	p := page{Version: Version, People: make([]pagePerson, 0, len(r.People)), IncludeRelatives: &r.IncludeRelatives}
+	for _, person := range r.People {
+		p.People = append(p.People, pagePerson{
+			Name:           person.Name,
+			SurnameInitial: person.SurnameInitial,
+			Parents:        nonNil(person.Parents),
+			Grandparents:   nonNil(person.Grandparents),
+			Gender:         person.Gender,
+		})
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	// JSON.stringify in the page doesn't escape HTML characters either
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(p); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(bytes.TrimSuffix(b.Bytes(), []byte("\n"))), nil
+}
+
+// ShareURL returns the page URL base with the roster in its names
+// parameter, keeping the other parameters of base
+func (r *Roster) ShareURL(base string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid page URL: %w", err)
+	}
+
+	encoded, err := r.EncodeURL()
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	q.Set(URLParam, encoded)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// DecodeURL decodes a roster from a shared link or the value of its names
+// parameter. Besides the current form it reads links shared before
+// versioning and the oldest links, which held a plain list of names
+// separated by commas or new lines. The result is migrated to the current
+// version.
+func DecodeURL(s string) (*Roster, error) {
+	s = strings.TrimSpace(s)
+	if u, err := url.Parse(s); err == nil && u.Scheme != "" {
+		s = u.Query().Get(URLParam)
+		if s == "" {
+			return nil, fmt.Errorf("the link has no %s parameter", URLParam)
+		}
+	}
+
+	data, err := decodeBase64(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: %w", URLParam, err)
+	}
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("invalid %s parameter: not UTF-8 text", URLParam)
+	}
+
+	r := New()
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var p page
+		if err := json.Unmarshal(trimmed, &p); err != nil {
+			return nil, fmt.Errorf("invalid %s parameter: %w", URLParam, err)
+		}
+		if p.Version > Version {
+			return nil, fmt.Errorf("unsupported roster version %d, expected at most %d", p.Version, Version)
+		}
+
+		for _, person := range p.People {
+			r.People = append(r.People, scoring.Person{
+				Name:           strings.TrimSpace(person.Name),
+				SurnameInitial: strings.TrimSpace(person.SurnameInitial),
+				Parents:        nilIfEmpty(person.Parents),
+				Grandparents:   nilIfEmpty(person.Grandparents),
+				Gender:         person.Gender,
+			})
+		}
+		if p.IncludeRelatives != nil {
+			r.IncludeRelatives = *p.IncludeRelatives
+		}
+	} else {
+		// The oldest links held the text of the names field
+		for _, name := range strings.FieldsFunc(string(data), func(r rune) bool { return r == ',' || r == '\n' || r == '\r' }) {
+			if name = strings.TrimSpace(name); name != "" {
+				r.People = append(r.People, scoring.Person{Name: name})
+			}
+		}
+	}
+
+	return r, r.Validate()
+}
+
+// decodeBase64 decodes standard or URL-safe base64 with or without padding.
+// Spaces are read as "+", which query strings without escaping turn into.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.NewReplacer(" ", "+", "-", "+", "_", "/").Replace(s)
+	s = strings.TrimRight(s, "=")
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+func nilIfEmpty(list []string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
@@ -0,0 +1,101 @@
+package roster
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/scoring"
+)
+
+// pageEncoded is what index.html's encodeNames produces for pageRoster
+const pageEncoded = "eyJ2ZXJzaW9uIjoyLCJwZW9wbGUiOlt7Im5hbWUiOiLQmtGB0LXQvdC40Y8iLCJzdXJuYW1lSW5pdGlhbCI6ItCfLiIsInBhcmVudHMiOlsi0JjQstCw0L0iLCLQnNCw0YDQuNGPIl0sImdyYW5kcGFyZW50cyI6W10sImdlbmRlciI6ImZlbWFsZSJ9LHsibmFtZSI6ItCv0L0gJiA80JrQvj4iLCJzdXJuYW1lSW5pdGlhbCI6IiIsInBhcmVudHMiOltdLCJncmFuZHBhcmVudHMiOlsi0JDQvdC90LAiXX1dLCJpbmNsdWRlUmVsYXRpdmVzIjpmYWxzZX0="
+
+var pageRoster = &Roster{
+	Version: Version,
+	People: []scoring.Person{
+		{Name: "Ксения", SurnameInitial: "П.", Parents: []string{"Иван", "Мария"}, Gender: domain.Female},
+		{Name: "Ян & <Ко>", Grandparents: []string{"Анна"}},
+	},
+	IncludeRelatives: false,
+}
+
+func TestEncodeURL(t *testing.T) {
+	encoded, err := pageRoster.EncodeURL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != pageEncoded {
+		t.Errorf("Encoding differs from the page:\n%s\n%s", encoded, pageEncoded)
+	}
+
+	link, err := pageRoster.ShareURL("https://example.com/namedays/?x=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeURL(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, pageRoster) {
+		t.Errorf("Round trip through %s gives %+v", link, decoded)
+	}
+}
+
+func TestDecodeURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoded  string
+		expected *Roster
+	}{
+		{"current", pageEncoded, pageRoster},
+		{
+			// {"version":1,"people":[{"name":"Ксения","surnameInitial":"П.","parents":["Иван","Мария"],"grandparents":[]}],"includeRelatives":false}
+			"version 1",
+			"eyJ2ZXJzaW9uIjoxLCJwZW9wbGUiOlt7Im5hbWUiOiLQmtGB0LXQvdC40Y8iLCJzdXJuYW1lSW5pdGlhbCI6ItCfLiIsInBhcmVudHMiOlsi0JjQstCw0L0iLCLQnNCw0YDQuNGPIl0sImdyYW5kcGFyZW50cyI6W119XSwiaW5jbHVkZVJlbGF0aXZlcyI6ZmFsc2V9",
+			&Roster{Version: Version, People: []scoring.Person{{Name: "Ксения", SurnameInitial: "П.", Parents: []string{"Иван", "Мария"}}}},
+		},
+		{
+			// {"people":[{"name":"Анна","surnameInitial":"","parents":["Пётр"],"grandparents":[]}],"includeRelatives":true}
+			"before versioning",
+			"eyJwZW9wbGUiOlt7Im5hbWUiOiLQkNC90L3QsCIsInN1cm5hbWVJbml0aWFsIjoiIiwicGFyZW50cyI6WyLQn9GR0YLRgCJdLCJncmFuZHBhcmVudHMiOltdfV0sImluY2x1ZGVSZWxhdGl2ZXMiOnRydWV9",
+			New(scoring.Person{Name: "Анна", Parents: []string{"Пётр"}}),
+		},
+		{
+			// "Ксения, Иван\nМария" from the names text field
+			"names text",
+			"0JrRgdC10L3QuNGPLCDQmNCy0LDQvQrQnNCw0YDQuNGP",
+			New(scoring.Person{Name: "Ксения"}, scoring.Person{Name: "Иван"}, scoring.Person{Name: "Мария"}),
+		},
+		{
+			// "Ян, Ия" as 0K/QvSwg0JjRjw==
+			"url-safe without padding",
+			"0K_QvSwg0JjRjw",
+			New(scoring.Person{Name: "Ян"}, scoring.Person{Name: "Ия"}),
+		},
+		{
+			// "Анна, Фёдор" with the "+" an unescaped query string turns
+			// into a space
+			"space for plus",
+			"0JDQvdC90LAsINCk0ZHQtNC 0YA=",
+			New(scoring.Person{Name: "Анна"}, scoring.Person{Name: "Фёдор"}),
+		},
+	}
+
+	for _, tt := range tests {
+		r, err := DecodeURL(tt.encoded)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(r, tt.expected) {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.expected, r)
+		}
+	}
+
+	for _, bad := range []string{"", "!!!", "https://example.com/?other=1", "e30="} {
+		if _, err := DecodeURL(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
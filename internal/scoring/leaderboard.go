@@ -0,0 +1,162 @@
+package scoring
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Standing is a person's place in the leaderboard
+type Standing struct {
+	Rank int `json:"rank"`
+	// Name is the display name of the person
+	Name string `json:"name"`
+	// Total is the score from January 1 to today
+	Total float64 `json:"total"`
+	// Relatives is the part of Total brought by relatives
+	Relatives float64 `json:"relatives"`
+	// OwnToday is set when today is the person's own nameday
+	OwnToday bool `json:"own_today"`
+	// TodayGain is the points scored today, own and relatives'
+	TodayGain float64 `json:"today_gain"`
+	// FullName is the full name scored when the person's name is a short
+	// form, and OtherFullNames the ones it may stand for as well
+	FullName       string   `json:"full_name,omitempty"`
+	OtherFullNames []string `json:"other_full_names,omitempty"`
+}
+
+// Leaderboard is the ranking of a roster
+type Leaderboard []Standing
+
+// Leaderboard ranks people by their score from January 1 to the day of
+// now, highest first. People with equal scores share a rank and keep the
+// order they were given in.
+func (s *Scorer) Leaderboard(people []Person, now time.Time) Leaderboard {
+	standings := make(Leaderboard, 0, len(people))
+	for _, p := range people {
+		result := s.ScoreYearToDate(p, now)
+		today := result.Last()
+		standings = append(standings, Standing{
+			Name:           p.DisplayName(),
+			Total:          result.Total,
+			Relatives:      result.RelativesTotal,
+			OwnToday:       today.Own > 0,
+			TodayGain:      today.Score(),
+			FullName:       result.FullName,
+			OtherFullNames: result.OtherFullNames,
+		})
+	}
+
+	slices.SortStableFunc(standings, func(a, b Standing) int {
+		switch {
+		case a.Total > b.Total:
+			return -1
+		case a.Total < b.Total:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	for i := range standings {
+		standings[i].Rank = i + 1
+		if i > 0 && standings[i].Total == standings[i-1].Total {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+
+	return standings
+}
+
+// Format is an output format for a Leaderboard
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatCSV   Format = "csv"
+)
+
+// ParseFormat parses a format name as given on the command line
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatTable, FormatJSON, FormatCSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown format: %s (expected table, json or csv)", s)
+	}
+}
+
+// Write renders the leaderboard in the given format
+func (l Leaderboard) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(l)
+	case FormatCSV:
+		return l.writeCSV(w)
+	default:
+		return l.writeTable(w)
+	}
+}
+
+func (l Leaderboard) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tName\tTotal\tRelatives\tToday")
+	for _, s := range l {
+		today := ""
+		if s.TodayGain > 0 {
+			today = "+" + formatPoints(s.TodayGain)
+		}
+		name := s.Name
+		if s.FullName != "" {
+			// A question mark flags a short form that may stand for
+			// other names too
+			name += " (" + s.FullName
+			if len(s.OtherFullNames) > 0 {
+				name += "?"
+			}
+			name += ")"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", s.Rank, name, formatPoints(s.Total), formatPoints(s.Relatives), today)
+	}
+	return tw.Flush()
+}
+
+func (l Leaderboard) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"rank", "name", "total", "relatives", "own_today", "today_gain", "full_name", "other_full_names"}); err != nil {
+		return err
+	}
+
+	for _, s := range l {
+		record := []string{
+			strconv.Itoa(s.Rank),
+			s.Name,
+			formatPoints(s.Total),
+			formatPoints(s.Relatives),
+			strconv.FormatBool(s.OwnToday),
+			formatPoints(s.TodayGain),
+			s.FullName,
+			strings.Join(s.OtherFullNames, ", "),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatPoints formats points without trailing zeros, e.g. 2.25 or 3
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
+}
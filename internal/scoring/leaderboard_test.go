@@ -0,0 +1,91 @@
+package scoring
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/domain/domaintest"
+	"github.com/kvloginov/namedays/internal/names"
+	"github.com/kvloginov/namedays/internal/query"
+)
+
+func TestLeaderboard(t *testing.T) {
+	s := testScorer(t)
+	people := []Person{
+		{Name: "Илья"},
+		{Name: "Анна", SurnameInitial: "К.", Parents: []string{"Сергей"}},
+		{Name: "Мария"},
+		{Name: "Ян"},
+	}
+
+	leaderboard := s.Leaderboard(people, time.Date(2026, time.January, 3, 12, 0, 0, 0, time.UTC))
+
+	expected := Leaderboard{
+		{Rank: 1, Name: "Анна К.", Total: 1.5, Relatives: 0.5, OwnToday: true, TodayGain: 1},
+		{Rank: 2, Name: "Илья", Total: 1},
+		{Rank: 2, Name: "Мария", Total: 1},
+		{Rank: 4, Name: "Ян"},
+	}
+	if !reflect.DeepEqual(leaderboard, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, leaderboard)
+	}
+
+	var b strings.Builder
+	if err := leaderboard.Write(&b, FormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	expectedCSV := "rank,name,total,relatives,own_today,today_gain,full_name,other_full_names\n" +
+		"1,Анна К.,1.5,0.5,true,1,,\n" +
+		"2,Илья,1,0,false,0,,\n" +
+		"2,Мария,1,0,false,0,,\n" +
+		"4,Ян,0,0,false,0,,\n"
+	if b.String() != expectedCSV {
+		t.Errorf("Unexpected CSV:\n%s", b.String())
+	}
+
+	b.Reset()
+	if err := leaderboard.Write(&b, FormatTable); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(b.String()), "\n"); len(lines) != 5 || !strings.HasSuffix(lines[1], "+1") {
+		t.Errorf("Unexpected table:\n%s", b.String())
+	}
+}
+
+func TestLeaderboardDiminutive(t *testing.T) {
+	namedays := domain.NamedaysDataList{
+		{Date: domaintest.Day(t, time.January, 1), Names: []string{"Александр"}},
+		{Date: domaintest.Day(t, time.January, 2), Names: []string{"Александра"}},
+	}
+	dict := &names.Dictionary{
+		Version: names.DictionaryVersion,
+		Diminutives: []names.Diminutive{
+			{Full: "Александр", Short: []string{"Саша"}},
+			{Full: "Александра", Short: []string{"Саша"}},
+		},
+	}
+	s := NewScorer(query.New(namedays, names.NewNormalizer(dict)))
+
+	people := []Person{{Name: "Саша"}, {Name: "Саша", SurnameInitial: "К.", Gender: domain.Female}}
+	leaderboard := s.Leaderboard(people, time.Date(2026, time.January, 3, 12, 0, 0, 0, time.UTC))
+
+	// A short form scores one full name, not all of them
+	expected := Leaderboard{
+		{Rank: 1, Name: "Саша", Total: 1, FullName: "Александр", OtherFullNames: []string{"Александра"}},
+		{Rank: 1, Name: "Саша К.", Total: 1, FullName: "Александра"},
+	}
+	if !reflect.DeepEqual(leaderboard, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, leaderboard)
+	}
+
+	var b strings.Builder
+	if err := leaderboard.Write(&b, FormatTable); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "Саша (Александр?)") || !strings.Contains(b.String(), "Саша К. (Александра)") {
+		t.Errorf("Unexpected table:\n%s", b.String())
+	}
+}
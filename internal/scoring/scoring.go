@@ -0,0 +1,205 @@
+package scoring
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/query"
+)
+
+// Points of a nameday by whose nameday it is
+const (
+	OwnPoints         = 1
+	ParentPoints      = 0.5
+	GrandparentPoints = 0.25
+)
+
+// Person is someone taking part in the scoring, with the relatives whose
+// namedays also bring them points
+type Person struct {
+	Name string `json:"name" yaml:"name"`
+	// SurnameInitial tells apart people with the same name, e.g. "П."
+	SurnameInitial string   `json:"surname_initial,omitempty" yaml:"surname_initial,omitempty"`
+	Parents        []string `json:"parents,omitempty" yaml:"parents,omitempty"`
+	Grandparents   []string `json:"grandparents,omitempty" yaml:"grandparents,omitempty"`
+	// Gender chooses among the full names of a short form, e.g. Александра
+	// for a woman called Саша, optional
+	Gender domain.Gender `json:"gender,omitempty" yaml:"gender,omitempty"`
+}
+
+// DisplayName returns the name with the surname initial, if any
+func (p Person) DisplayName() string {
+	if initial := strings.TrimSpace(p.SurnameInitial); initial != "" {
+		return p.Name + " " + initial
+	}
+	return p.Name
+}
+
+// Day is the score of a person on one day
+type Day struct {
+	// Date is the day, YYYY-MM-DD
+	Date string `json:"date"`
+	// Own is 1 on the person's own nameday
+	Own float64 `json:"own"`
+	// Relatives is the sum of the parents' and grandparents' points
+	Relatives float64 `json:"relatives"`
+	// Cumulative is the sum of all points up to and including this day
+	Cumulative float64 `json:"cumulative"`
+}
+
+// Score is the sum of Own and Relatives
+func (d Day) Score() float64 {
+	return d.Own + d.Relatives
+}
+
+// Result is the score of a person over a date range
+type Result struct {
+	Person Person `json:"person"`
+	Days   []Day  `json:"days"`
+	// Total is the score of the whole range
+	Total float64 `json:"total"`
+	// RelativesTotal is the part of Total brought by relatives
+	RelativesTotal float64 `json:"relatives_total"`
+	// FullName is the full name scored when the person's name is a short
+	// form, like Александр for Саша
+	FullName string `json:"full_name,omitempty"`
+	// OtherFullNames are the other full names of the person's gender the
+	// short form may stand for. They aren't scored: setting the gender, or
+	// entering the full name, resolves the ambiguity.
+	OtherFullNames []string `json:"other_full_names,omitempty"`
+}
+
+// Last returns the last day of the range, e.g. today's gain when the range
+// ends today. It returns a zero Day for an empty range.
+func (r Result) Last() Day {
+	if len(r.Days) == 0 {
+		return Day{}
+	}
+	return r.Days[len(r.Days)-1]
+}
+
+// Scorer scores people by the namedays of a dataset
+type Scorer struct {
+	index *query.Index
+	// IncludeRelatives counts the namedays of parents and grandparents
+	IncludeRelatives bool
+}
+
+// NewScorer creates a scorer over ix that includes relatives. Names match
+// as whole names through ix, so "Ян" doesn't match "Иоанн" unless the
+// names dictionary says they are the same name. A short form like Саша
+// scores the namedays of one of its full names, see Resolve.
+func NewScorer(ix *query.Index) *Scorer {
+	return &Scorer{index: ix, IncludeRelatives: true}
+}
+
+// Score returns the daily and cumulative scores of p for every day from
+// from to to, both included. Only the dates of from and to matter, in
+// their own location. Namedays on Feb 29 count on Feb 28 in non-leap years,
+// as in the year calendar.
+func (s *Scorer) Score(p Person, from, to time.Time) Result {
+	fullName, others := s.Resolve(p.Name, p.Gender)
+	own := s.dates(fullName)
+	var parents, grandparents []map[domain.DayMonth]bool
+	if s.IncludeRelatives {
+		for _, name := range p.Parents {
+			parents = append(parents, s.relativeDates(name))
+		}
+		for _, name := range p.Grandparents {
+			grandparents = append(grandparents, s.relativeDates(name))
+		}
+	}
+
+	result := Result{Person: p, OtherFullNames: others}
+	if fullName != p.Name {
+		result.FullName = fullName
+	}
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	for t := start; !t.After(end); t = t.AddDate(0, 0, 1) {
+		var day Day
+		day.Date = t.Format(time.DateOnly)
+		if celebrates(own, t) {
+			day.Own = OwnPoints
+		}
+		for _, dates := range parents {
+			if celebrates(dates, t) {
+				day.Relatives += ParentPoints
+			}
+		}
+		for _, dates := range grandparents {
+			if celebrates(dates, t) {
+				day.Relatives += GrandparentPoints
+			}
+		}
+
+		result.Total += day.Score()
+		result.RelativesTotal += day.Relatives
+		day.Cumulative = result.Total
+		result.Days = append(result.Days, day)
+	}
+
+	return result
+}
+
+// ScoreYearToDate scores p from January 1 to the day of now, like the
+// chart of the web page
+func (s *Scorer) ScoreYearToDate(p Person, now time.Time) Result {
+	return s.Score(p, time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location()), now)
+}
+
+// relativeDates returns the nameday dates of a relative, whose gender
+// isn't known
+func (s *Scorer) relativeDates(name string) map[domain.DayMonth]bool {
+	fullName, _ := s.Resolve(name, domain.GenderUnknown)
+	return s.dates(fullName)
+}
+
+// dates returns the nameday dates of name
+func (s *Scorer) dates(name string) map[domain.DayMonth]bool {
+	dates := make(map[domain.DayMonth]bool)
+	for _, m := range s.index.ByName(name) {
+		dates[m.Date] = true
+	}
+	return dates
+}
+
+// Resolve returns the name to score for name. A short form the index knows
+// only as such, like Саша, stands for one of its full names: the first of
+// gender g, or of any gender if g is unknown, men's names first. others
+// are the remaining full names it may stand for. A short form without full
+// names of gender g resolves to "", which scores nothing.
+func (s *Scorer) Resolve(name string, g domain.Gender) (fullName string, others []string) {
+	matches := s.index.ByName(name)
+	if len(matches) == 0 || matches[0].Diminutive == "" {
+		return name, nil
+	}
+
+	var candidates []string
+	for _, c := range s.index.Candidates(name) {
+		if g == domain.GenderUnknown || c.Gender == g {
+			candidates = append(candidates, c.Name)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return "", nil
+	case 1:
+		return candidates[0], nil
+	default:
+		return candidates[0], candidates[1:]
+	}
+}
+
+// celebrates reports whether one of dates falls on t
+func celebrates(dates map[domain.DayMonth]bool, t time.Time) bool {
+	date := domain.NewDayMonth(t)
+	if dates[date] {
+		return true
+	}
+
+	// Feb 29 namedays move to Feb 28 in non-leap years
+	feb29, _ := domain.MakeDayMonth(time.February, 29)
+	return date.Next() == feb29 && !feb29.ExistsIn(t.Year()) && dates[feb29]
+}
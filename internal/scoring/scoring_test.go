@@ -0,0 +1,137 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/domain/domaintest"
+	"github.com/kvloginov/namedays/internal/names"
+	"github.com/kvloginov/namedays/internal/query"
+)
+
+func date(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func testScorer(t *testing.T) *Scorer {
+	namedays := domain.NamedaysDataList{
+		{Date: domaintest.Day(t, time.January, 1), Names: []string{"Илья", "Иоанн"}},
+		{Date: domaintest.Day(t, time.January, 2), Names: []string{"Сергей", "Мария"}, Aliases: map[string][]string{"Сергей": {"Сергий"}}},
+		{Date: domaintest.Day(t, time.January, 3), Names: []string{"Пётр", "Анна"}},
+		{Date: domaintest.Day(t, time.February, 29), Names: []string{"Кассиан"}},
+	}
+	return NewScorer(query.New(namedays, names.NewNormalizer(nil)))
+}
+
+func TestScore(t *testing.T) {
+	s := testScorer(t)
+	p := Person{Name: "Сергий", Parents: []string{"Петр", "мария"}, Grandparents: []string{"Анна", "Илья"}}
+
+	result := s.Score(p, date(2026, time.January, 1), date(2026, time.January, 4))
+
+	expected := []Day{
+		{Date: "2026-01-01", Own: 0, Relatives: 0.25, Cumulative: 0.25},
+		{Date: "2026-01-02", Own: 1, Relatives: 0.5, Cumulative: 1.75},
+		{Date: "2026-01-03", Own: 0, Relatives: 0.75, Cumulative: 2.5},
+		{Date: "2026-01-04", Own: 0, Relatives: 0, Cumulative: 2.5},
+	}
+	if !reflect.DeepEqual(result.Days, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result.Days)
+	}
+	if result.Total != 2.5 || result.RelativesTotal != 1.5 {
+		t.Errorf("Unexpected totals %v and %v", result.Total, result.RelativesTotal)
+	}
+	if result.Last() != expected[3] {
+		t.Errorf("Unexpected last day %+v", result.Last())
+	}
+
+	s.IncludeRelatives = false
+	if result := s.Score(p, date(2026, time.January, 1), date(2026, time.January, 4)); result.Total != 1 || result.RelativesTotal != 0 {
+		t.Errorf("Expected only the own nameday without relatives, got %v", result.Total)
+	}
+}
+
+func TestScoreMatchesWholeNames(t *testing.T) {
+	s := testScorer(t)
+
+	// The web page matched substrings, so "Ян" scored on the days of Иоанн
+	// and "Ан" on the days of Анна and Кассиан
+	for _, name := range []string{"Ян", "Ан", "Ил"} {
+		if result := s.Score(Person{Name: name}, date(2026, time.January, 1), date(2026, time.December, 31)); result.Total != 0 {
+			t.Errorf("%s scored %v", name, result.Total)
+		}
+	}
+}
+
+func TestScoreFeb29(t *testing.T) {
+	s := testScorer(t)
+	p := Person{Name: "Кассиан"}
+
+	// Non-leap years celebrate on Feb 28
+	result := s.Score(p, date(2026, time.February, 27), date(2026, time.March, 1))
+	if result.Days[1].Own != 1 || result.Total != 1 {
+		t.Errorf("Expected the nameday on Feb 28, got %+v", result.Days)
+	}
+
+	result = s.Score(p, date(2028, time.February, 27), date(2028, time.March, 1))
+	if result.Days[1].Own != 0 || result.Days[2].Own != 1 || result.Total != 1 {
+		t.Errorf("Expected the nameday on Feb 29, got %+v", result.Days)
+	}
+}
+
+func TestScoreYearToDate(t *testing.T) {
+	s := testScorer(t)
+
+	result := s.ScoreYearToDate(Person{Name: "Анна"}, time.Date(2026, time.January, 3, 18, 0, 0, 0, time.UTC))
+	if len(result.Days) != 3 || result.Last().Own != 1 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	if name := (Person{Name: "Анна", SurnameInitial: " П. "}).DisplayName(); name != "Анна П." {
+		t.Errorf("Unexpected display name %q", name)
+	}
+	if name := (Person{Name: "Анна"}).DisplayName(); name != "Анна" {
+		t.Errorf("Unexpected display name %q", name)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	namedays := domain.NamedaysDataList{
+		{Date: domaintest.Day(t, time.January, 1), Names: []string{"Александр", "Алексей"}},
+		{Date: domaintest.Day(t, time.January, 2), Names: []string{"Александра"}},
+	}
+	dict := &names.Dictionary{
+		Version: names.DictionaryVersion,
+		Diminutives: []names.Diminutive{
+			{Full: "Александр", Short: []string{"Саша"}},
+			{Full: "Александра", Short: []string{"Саша"}},
+			{Full: "Алексей", Short: []string{"Лёша"}},
+		},
+	}
+	s := NewScorer(query.New(namedays, names.NewNormalizer(dict)))
+
+	tests := []struct {
+		name     string
+		gender   domain.Gender
+		expected string
+		others   []string
+	}{
+		{"Алексей", domain.GenderUnknown, "Алексей", nil},
+		{"Саша", domain.GenderUnknown, "Александр", []string{"Александра"}},
+		{"Саша", domain.Female, "Александра", nil},
+		{"Sasha", domain.Male, "Александр", nil},
+		{"Лёша", domain.GenderUnknown, "Алексей", nil},
+		// No full name of that gender
+		{"Лёша", domain.Female, "", nil},
+	}
+	for _, tt := range tests {
+		got, others := s.Resolve(tt.name, tt.gender)
+		if got != tt.expected || !reflect.DeepEqual(others, tt.others) {
+			t.Errorf("Resolve(%q, %q) = %q, %v, expected %q, %v", tt.name, tt.gender, got, others, tt.expected, tt.others)
+		}
+	}
+}
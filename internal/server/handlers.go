@@ -0,0 +1,441 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/ical"
+	"github.com/kvloginov/namedays/internal/names"
+	"github.com/kvloginov/namedays/internal/query"
+	"github.com/kvloginov/namedays/internal/roster"
+	"github.com/kvloginov/namedays/internal/scoring"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	// maxRosterSize limits the roster posted to /v1/leaderboard
+	maxRosterSize = 1 << 20
+)
+
+// dayResponse lists the names celebrated on a date
+type dayResponse struct {
+	Date  domain.DayMonth `json:"date"`
+	Label string          `json:"label"`
+	Names []query.Match   `json:"names"`
+}
+
+type todayResponse struct {
+	Today    string `json:"today"`
+	TimeZone string `json:"tz"`
+	dayResponse
+}
+
+type nameResponse struct {
+	Name string `json:"name"`
+	// FullNames are the full names Name stands for when it is a short form
+	FullNames []names.Candidate `json:"full_names,omitempty"`
+	Dates     []query.Match     `json:"dates"`
+}
+
+type nextResponse struct {
+	query.Match
+	// On is the day the nameday falls on, YYYY-MM-DD
+	On string `json:"on"`
+}
+
+type searchResponse struct {
+	Query   string            `json:"query"`
+	Results []query.NameDates `json:"results"`
+}
+
+type leaderboardResponse struct {
+	// Date is the last day of the competition, YYYY-MM-DD
+	Date             string              `json:"date"`
+	IncludeRelatives bool                `json:"include_relatives"`
+	Standings        scoring.Leaderboard `json:"standings"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) handleDay(w http.ResponseWriter, r *http.Request) {
+	date, err := domain.ParseDayMonth(r.PathValue("date"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid date, expected MMDD: "+r.PathValue("date"))
+		return
+	}
+
+	data := s.snapshot()
+	index, ok := requestIndex(w, r, data)
+	if !ok {
+		return
+	}
+	s.respond(w, r, data, "", newDayResponse(index, date))
+}
+
+func (s *Server) handleToday(w http.ResponseWriter, r *http.Request) {
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	data := s.snapshot()
+	index, ok := requestIndex(w, r, data)
+	if !ok {
+		return
+	}
+
+	now := s.now().In(loc)
+	today := now.Format(time.DateOnly)
+	// The answer changes at midnight, so it is cached per day
+	s.respond(w, r, data, today, todayResponse{
+		Today:       today,
+		TimeZone:    loc.String(),
+		dayResponse: newDayResponse(index, domain.NewDayMonth(now)),
+	})
+}
+
+func (s *Server) handleName(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	data := s.snapshot()
+	index, ok := requestIndex(w, r, data)
+	if !ok {
+		return
+	}
+
+	matches := index.ByName(name)
+	if len(matches) == 0 {
+		writeError(w, http.StatusNotFound, "no namedays for "+name)
+		return
+	}
+	response := nameResponse{Name: name, Dates: matches}
+	if matches[0].Diminutive != "" {
+		response.FullNames = index.Candidates(name)
+	}
+	s.respond(w, r, data, "", response)
+}
+
+func (s *Server) handleNext(w http.ResponseWriter, r *http.Request) {
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	from := s.now().In(loc)
+	if value := r.URL.Query().Get("from"); value != "" {
+		var err error
+		if from, err = time.ParseInLocation(time.DateOnly, value, loc); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid from date, expected YYYY-MM-DD: "+value)
+			return
+		}
+	}
+
+	name := r.PathValue("name")
+	data := s.snapshot()
+	index, ok := requestIndex(w, r, data)
+	if !ok {
+		return
+	}
+	match, on, found := index.Next(name, from)
+	if !found {
+		writeError(w, http.StatusNotFound, "no namedays for "+name)
+		return
+	}
+	s.respond(w, r, data, from.Format(time.DateOnly), nextResponse{Match: match, On: on.Format(time.DateOnly)})
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		writeError(w, http.StatusBadRequest, "missing query parameter q")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxSearchLimit {
+			writeError(w, http.StatusBadRequest, "limit must be a number from 1 to "+strconv.Itoa(maxSearchLimit))
+			return
+		}
+		limit = n
+	}
+
+	data := s.snapshot()
+	index, ok := requestIndex(w, r, data)
+	if !ok {
+		return
+	}
+	results := index.Search(q, limit)
+	if results == nil {
+		results = []query.NameDates{}
+	}
+	s.respond(w, r, data, "", searchResponse{Query: q, Results: results})
+}
+
+// handleCalendar serves the namedays of the names in the name parameters
+// as an iCalendar feed that calendar apps can subscribe to
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	var wanted []string
+	for _, value := range params["name"] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				wanted = append(wanted, name)
+			}
+		}
+	}
+	if len(wanted) == 0 {
+		writeError(w, http.StatusBadRequest, "missing query parameter name")
+		return
+	}
+
+	calendar := ical.Calendar{Name: "Именины: " + strings.Join(wanted, ", "), Stamp: s.now()}
+	if value := params.Get("remind"); value != "" {
+		remind, err := time.ParseDuration(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid remind duration, expected e.g. 15h: "+value)
+			return
+		}
+		calendar.Reminder = remind
+	}
+
+	data := s.snapshot()
+	index, ok := requestIndex(w, r, data)
+	if !ok {
+		return
+	}
+	var body bytes.Buffer
+	if err := calendar.Write(&body, ical.EventsFor(index, wanted)); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if fresh(w, r, data, "") {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="namedays.ics"`)
+	_, _ = w.Write(body.Bytes())
+}
+
+// handleLeaderboard ranks the roster of the names parameter, as in a link to
+// the web page, the roster in the body of a POST, or else the server's
+// roster, from January 1 to today or the date parameter
+func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	format := scoring.FormatJSON
+	if value := params.Get("format"); value != "" {
+		var err error
+		if format, err = scoring.ParseFormat(value); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+	now := s.now().In(loc)
+	if value := params.Get("date"); value != "" {
+		var err error
+		if now, err = time.ParseInLocation(time.DateOnly, value, loc); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid date, expected YYYY-MM-DD: "+value)
+			return
+		}
+	}
+
+	data := s.snapshot()
+	team := data.roster
+	if link := params.Get(roster.URLParam); link != "" {
+		var err error
+		if team, err = roster.DecodeURL(link); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else if r.Method == http.MethodPost {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRosterSize))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "error reading roster: "+err.Error())
+			return
+		}
+
+		name := "roster.json"
+		if strings.Contains(r.Header.Get("Content-Type"), "yaml") {
+			name = "roster.yaml"
+		}
+		if team, err = roster.ParseFile(name, body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid roster: "+err.Error())
+			return
+		}
+	} else if team == nil {
+		writeError(w, http.StatusNotFound, "the server has no roster, POST one instead")
+		return
+	}
+
+	scorer := scoring.NewScorer(data.index)
+	scorer.IncludeRelatives = team.IncludeRelatives
+	date := now.Format(time.DateOnly)
+	leaderboard := scorer.Leaderboard(team.People, now)
+
+	// Posted rosters aren't part of the data files, so they aren't cached.
+	// A roster from the URL is, since the URL is part of the cache key.
+	if r.Method == http.MethodGet && fresh(w, r, data, date+"-"+string(format)) {
+		return
+	}
+
+	switch format {
+	case scoring.FormatJSON:
+		writeJSON(w, http.StatusOK, leaderboardResponse{Date: date, IncludeRelatives: team.IncludeRelatives, Standings: leaderboard})
+	case scoring.FormatCSV:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		_ = leaderboard.Write(w, format)
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = leaderboard.Write(w, format)
+	}
+}
+
+func newDayResponse(index *query.Index, date domain.DayMonth) dayResponse {
+	matches := index.ByDate(date)
+	if matches == nil {
+		matches = []query.Match{}
+	}
+	return dayResponse{Date: date, Label: date.Russian(), Names: matches}
+}
+
+// requestIndex returns the index of the names of the gender parameter, or
+// of all names without one. It answers 400 for an unknown gender.
+func requestIndex(w http.ResponseWriter, r *http.Request, data *dataset) (*query.Index, bool) {
+	value := r.URL.Query().Get("gender")
+	if value == "" {
+		return data.index, true
+	}
+
+	gender, err := domain.ParseGender(value)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return data.byGender[gender], true
+}
+
+// requestLocation returns the time zone from the tz parameter, or the
+// server's default. It answers 400 for an unknown zone.
+func (s *Server) requestLocation(w http.ResponseWriter, r *http.Request) (*time.Location, bool) {
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		return s.location, true
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "unknown time zone: "+tz)
+		return nil, false
+	}
+	return loc, true
+}
+
+// respond writes v as JSON, or 304 Not Modified if the client's copy is
+// still fresh
+func (s *Server) respond(w http.ResponseWriter, r *http.Request, data *dataset, variant string, v any) {
+	if fresh(w, r, data, variant) {
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+// fresh sets the validators of a response and answers 304 Not Modified if
+// the client's copy is still fresh, reporting whether it did. Responses that
+// also depend on something besides the data files, like the current day,
+// pass it as variant: it becomes part of the ETag and Last-Modified is left
+// out.
+func fresh(w http.ResponseWriter, r *http.Request, data *dataset, variant string) bool {
+	etag := data.etag
+	if variant != "" {
+		etag = strings.TrimSuffix(etag, `"`) + "-" + variant + `"`
+	}
+
+	header := w.Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "no-cache")
+	if variant == "" {
+		header.Set("Last-Modified", data.modified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, variant == "", data.modified) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// notModified evaluates the conditional headers of r. If-None-Match takes
+// precedence over If-Modified-Since, as in RFC 9110.
+func notModified(r *http.Request, etag string, useModified bool, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && useModified {
+		since, err := http.ParseTime(ims)
+		return err == nil && !modified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// cors adds CORS headers to every response and answers preflight requests
+func (s *Server) cors(next http.Handler) http.Handler {
+	if s.allowOrigin == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		header.Set("Access-Control-Allow-Origin", s.allowOrigin)
+		header.Set("Access-Control-Expose-Headers", "ETag, Last-Modified")
+		if s.allowOrigin != "*" {
+			header.Add("Vary", "Origin")
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			header.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			header.Set("Access-Control-Allow-Headers", "Content-Type, If-None-Match, If-Modified-Since")
+			header.Set("Access-Control-Max-Age", "86400")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,272 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/names"
+	"github.com/kvloginov/namedays/internal/query"
+	"github.com/kvloginov/namedays/internal/roster"
+)
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithDictionary sets the name variants dictionary used to match names. It
+// is reloaded together with the dataset.
+func WithDictionary(path string) Option {
+	return func(s *Server) {
+		s.dictPath = path
+	}
+}
+
+// WithRoster sets the roster file /v1/leaderboard ranks. It is reloaded
+// together with the dataset.
+func WithRoster(path string) Option {
+	return func(s *Server) {
+		s.rosterPath = path
+	}
+}
+
+// WithAllowOrigin sets the Access-Control-Allow-Origin of responses, "*"
+// by default. An empty origin disables CORS headers.
+func WithAllowOrigin(origin string) Option {
+	return func(s *Server) {
+		s.allowOrigin = origin
+	}
+}
+
+// WithLocation sets the time zone /v1/today uses when the request has
+// none
+func WithLocation(loc *time.Location) Option {
+	return func(s *Server) {
+		s.location = loc
+	}
+}
+
+// WithLogger sets the logger for reloads and failed requests
+func WithLogger(logger *log.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithClock overrides the current time, for tests
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// Server serves the namedays dataset over HTTP as JSON
+type Server struct {
+	path        string
+	dictPath    string
+	rosterPath  string
+	allowOrigin string
+	location    *time.Location
+	logger      *log.Logger
+	now         func() time.Time
+
+	mu   sync.RWMutex
+	data *dataset
+}
+
+// dataset is a loaded snapshot of the data files
+type dataset struct {
+	namedays domain.NamedaysDataList
+	index    *query.Index
+	// byGender holds the indexes of the male and the female names
+	byGender map[domain.Gender]*query.Index
+	// roster is nil when the server has no roster file
+	roster *roster.Roster
+	// etag identifies the contents of the data files
+	etag string
+	// modified is the latest modification time of the data files
+	modified time.Time
+	// files are the stats the snapshot was loaded from, to detect changes
+	files []fileStamp
+}
+
+type fileStamp struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// New creates a server over the namedays file at path and loads it
+func New(path string, opts ...Option) (*Server, error) {
+	s := &Server{
+		path:        path,
+		allowOrigin: "*",
+		location:    time.UTC,
+		logger:      log.Default(),
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload reads the data files again. On error the server keeps serving
+// the previous data.
+func (s *Server) Reload() error {
+	data, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.data = data
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Server) load() (*dataset, error) {
+	data := &dataset{}
+	hash := sha256.New()
+	read := func(path string) ([]byte, error) {
+		stamp, err := stat(path)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading file %s: %w", path, err)
+		}
+
+		data.files = append(data.files, stamp)
+		if stamp.modTime.After(data.modified) {
+			data.modified = stamp.modTime
+		}
+		hash.Write(content)
+		return content, nil
+	}
+
+	content, err := read(s.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &data.namedays); err != nil {
+		return nil, fmt.Errorf("error unmarshalling file %s: %w", s.path, err)
+	}
+
+	var dict *names.Dictionary
+	if s.dictPath != "" {
+		content, err := read(s.dictPath)
+		if err != nil {
+			return nil, err
+		}
+		if dict, err = names.ParseDictionary(content); err != nil {
+			return nil, fmt.Errorf("error loading %s: %w", s.dictPath, err)
+		}
+	}
+
+	if s.rosterPath != "" {
+		content, err := read(s.rosterPath)
+		if err != nil {
+			return nil, err
+		}
+		if data.roster, err = roster.ParseFile(s.rosterPath, content); err != nil {
+			return nil, fmt.Errorf("error loading roster %s: %w", s.rosterPath, err)
+		}
+	}
+
+	data.index = query.New(data.namedays, names.NewNormalizer(dict))
+	data.byGender = map[domain.Gender]*query.Index{
+		domain.Male:   data.index.OnlyGender(domain.Male),
+		domain.Female: data.index.OnlyGender(domain.Female),
+	}
+	data.etag = `"` + hex.EncodeToString(hash.Sum(nil)[:12]) + `"`
+	return data, nil
+}
+
+// Watch checks the data files every interval and reloads them when they
+// change, until ctx is done
+func (s *Server) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// failed holds the files of the last failed reload, so that a broken
+	// file is reported once rather than on every tick
+	var failed []fileStamp
+	var lastErr string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := s.stamps()
+		if err == nil && (slices.Equal(current, s.snapshot().files) || slices.Equal(current, failed)) {
+			continue
+		}
+
+		if err := s.Reload(); err != nil {
+			if err.Error() != lastErr {
+				s.logger.Printf("Keeping the previous data, reload failed: %v", err)
+			}
+			failed, lastErr = current, err.Error()
+			continue
+		}
+		failed, lastErr = nil, ""
+		s.logger.Printf("Reloaded %s", s.path)
+	}
+}
+
+// stamps returns the current stats of the data files
+func (s *Server) stamps() ([]fileStamp, error) {
+	var result []fileStamp
+	for _, loaded := range s.snapshot().files {
+		current, err := stat(loaded.path)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, current)
+	}
+	return result, nil
+}
+
+func (s *Server) snapshot() *dataset {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data
+}
+
+func stat(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, fmt.Errorf("error reading file %s: %w", path, err)
+	}
+	return fileStamp{path: path, size: info.Size(), modTime: info.ModTime()}, nil
+}
+
+// Handler returns the HTTP handler of the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/days/{date}", s.handleDay)
+	mux.HandleFunc("GET /v1/today", s.handleToday)
+	mux.HandleFunc("GET /v1/names/{name}", s.handleName)
+	mux.HandleFunc("GET /v1/names/{name}/next", s.handleNext)
+	mux.HandleFunc("GET /v1/search", s.handleSearch)
+	mux.HandleFunc("GET /v1/calendar.ics", s.handleCalendar)
+	mux.HandleFunc("GET /v1/leaderboard", s.handleLeaderboard)
+	mux.HandleFunc("POST /v1/leaderboard", s.handleLeaderboard)
+	return s.cors(mux)
+}
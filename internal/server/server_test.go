@@ -0,0 +1,301 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/names"
+)
+
+const testDataset = `[
+	{"date": "0124", "names": ["Ксения", "Пётр"], "sources": {"Ксения": [{"source": "calend", "spelling": "Ксения"}]},
+		"meta": {"Пётр": {"saints": [{"title": "прп. Петр Галатийский", "type": "venerable", "url": "https://example.com/petr/"}]}}},
+	{"date": "0606", "names": ["Ксения"]},
+	{"date": "0301", "names": ["Иван"], "aliases": {"Иван": ["Иоанн"]}}
+]`
+
+// now is 23:30 on Jan 23 in UTC, already Jan 24 in Moscow
+var now = time.Date(2026, time.January, 23, 23, 30, 0, 0, time.UTC)
+
+func newTestServer(t *testing.T, dataset string, opts ...Option) (*Server, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "namedays.json")
+	if err := os.WriteFile(path, []byte(dataset), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts = append([]Option{
+		WithClock(func() time.Time { return now }),
+		WithLogger(log.New(io.Discard, "", 0)),
+	}, opts...)
+	s, err := New(path, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	return s, path
+}
+
+func get(t *testing.T, h http.Handler, target string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", w.Body.String(), err)
+	}
+	return body
+}
+
+func TestEndpoints(t *testing.T) {
+	s, _ := newTestServer(t, testDataset)
+	h := s.Handler()
+
+	tests := []struct {
+		target string
+		status int
+		key    string
+		value  any
+	}{
+		{"/v1/days/0124", http.StatusOK, "label", "24 января"},
+		{"/v1/days/0125", http.StatusOK, "names", []any{}},
+		{"/v1/days/0230", http.StatusBadRequest, "error", "invalid date, expected MMDD: 0230"},
+		{"/v1/today", http.StatusOK, "today", "2026-01-23"},
+		{"/v1/today?tz=Europe/Moscow", http.StatusOK, "date", "0124"},
+		{"/v1/today?tz=Nowhere/City", http.StatusBadRequest, "error", "unknown time zone: Nowhere/City"},
+		{"/v1/names/" + url.PathEscape("иоанн"), http.StatusOK, "name", "иоанн"},
+		{"/v1/names/" + url.PathEscape("Никто"), http.StatusNotFound, "error", "no namedays for Никто"},
+		{"/v1/names/" + url.PathEscape("ксения") + "/next", http.StatusOK, "on", "2026-01-24"},
+		{"/v1/names/" + url.PathEscape("ксения") + "/next?from=2026-10-18", http.StatusOK, "on", "2027-01-24"},
+		{"/v1/search?q=" + url.QueryEscape("ксе"), http.StatusOK, "query", "ксе"},
+		{"/v1/search", http.StatusBadRequest, "error", "missing query parameter q"},
+		{"/v1/days/0124?gender=female", http.StatusOK, "names", []any{map[string]any{
+			"date": "0124", "name": "Ксения", "gender": "female",
+			"sources": []any{map[string]any{"source": "calend", "spelling": "Ксения"}},
+		}}},
+		{"/v1/names/" + url.PathEscape("ксения") + "?gender=male", http.StatusNotFound, "error", "no namedays for ксения"},
+		{"/v1/names/" + url.PathEscape("петр"), http.StatusOK, "dates", []any{map[string]any{
+			"date": "0124", "name": "Пётр", "gender": "male",
+			"saints": []any{map[string]any{"title": "прп. Петр Галатийский", "type": "venerable", "url": "https://example.com/petr/"}},
+		}}},
+		{"/v1/names/Kseniya/next?from=2026-10-18", http.StatusOK, "name", "Ксения"},
+		{"/v1/search?q=Ksen", http.StatusOK, "results", []any{map[string]any{"name": "Ксения", "gender": "female", "dates": []any{"0124", "0606"}}}},
+		{"/v1/days/0124?gender=other", http.StatusBadRequest, "error", "unknown gender: other (expected male or female)"},
+	}
+
+	for _, tt := range tests {
+		w := get(t, h, tt.target, nil)
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.target, tt.status, w.Code, w.Body)
+			continue
+		}
+
+		got, _ := json.Marshal(decode(t, w)[tt.key])
+		expected, _ := json.Marshal(tt.value)
+		if string(got) != string(expected) {
+			t.Errorf("%s: expected %s = %s, got %s", tt.target, tt.key, expected, got)
+		}
+	}
+}
+
+func TestNameDiminutive(t *testing.T) {
+	dictPath := filepath.Join(t.TempDir(), "name_variants.json")
+	dict := `{"version": 1, "groups": [], "diminutives": [{"full": "Ксения", "short": ["Ксюша"]}, {"full": "Пётр", "short": ["Петя"]}]}`
+	if err := os.WriteFile(dictPath, []byte(dict), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := newTestServer(t, testDataset, WithDictionary(dictPath))
+	h := s.Handler()
+
+	w := get(t, h, "/v1/names/"+url.PathEscape("Ксюша"), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected response %d: %s", w.Code, w.Body)
+	}
+	var response nameResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	expected := []names.Candidate{{Name: "Ксения", Gender: domain.Female}}
+	if !reflect.DeepEqual(response.FullNames, expected) || len(response.Dates) != 2 || response.Dates[0].Diminutive != "Ксюша" {
+		t.Errorf("Unexpected response %+v", response)
+	}
+
+	if w := get(t, h, "/v1/names/Petya/next?from=2026-10-18", nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"diminutive":"Petya"`) {
+		t.Errorf("Unexpected response %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	s, _ := newTestServer(t, testDataset)
+	h := s.Handler()
+
+	w := get(t, h, "/v1/days/0124", nil)
+	etag, modified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	if etag == "" || modified == "" {
+		t.Fatalf("Expected validators, got headers %v", w.Header())
+	}
+
+	if w := get(t, h, "/v1/days/0124", http.Header{"If-None-Match": {etag}}); w.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match: expected 304, got %d", w.Code)
+	}
+	if w := get(t, h, "/v1/days/0124", http.Header{"If-Modified-Since": {modified}}); w.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since: expected 304, got %d", w.Code)
+	}
+	if w := get(t, h, "/v1/days/0124", http.Header{"If-None-Match": {`"other"`}}); w.Code != http.StatusOK {
+		t.Errorf("Stale ETag: expected 200, got %d", w.Code)
+	}
+
+	// Today's answer changes daily, so it must not be validated by date
+	w = get(t, h, "/v1/today", nil)
+	if w.Header().Get("Last-Modified") != "" || w.Header().Get("ETag") == etag {
+		t.Errorf("Unexpected validators for today: %v", w.Header())
+	}
+}
+
+func TestCORS(t *testing.T) {
+	s, _ := newTestServer(t, testDataset)
+	h := s.Handler()
+
+	if w := get(t, h, "/v1/days/0124", nil); w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("Expected CORS headers, got %v", w.Header())
+	}
+
+	r := httptest.NewRequest(http.MethodOptions, "/v1/days/0124", nil)
+	r.Header.Set("Origin", "https://example.com")
+	r.Header.Set("Access-Control-Request-Method", "GET")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Methods") == "" {
+		t.Errorf("Unexpected preflight response %d: %v", w.Code, w.Header())
+	}
+}
+
+func TestWatchReloads(t *testing.T) {
+	s, path := newTestServer(t, testDataset)
+	h := s.Handler()
+	etag := get(t, h, "/v1/days/0124", nil).Header().Get("ETag")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Watch(ctx, 10*time.Millisecond)
+
+	// A broken file keeps the previous data
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if w := get(t, h, "/v1/days/0124", nil); w.Code != http.StatusOK || w.Header().Get("ETag") != etag {
+		t.Fatalf("Expected the previous data after a failed reload, got %d %v", w.Code, w.Header())
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"date": "0125", "names": ["Григорий"]}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if names := decode(t, get(t, h, "/v1/days/0125", nil))["names"].([]any); len(names) == 1 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("The server didn't reload the changed file")
+}
+
+func TestCalendarFeed(t *testing.T) {
+	s, _ := newTestServer(t, testDataset)
+	h := s.Handler()
+
+	w := get(t, h, "/v1/calendar.ics?name="+url.QueryEscape("ксения,Иван")+"&remind=15h", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/calendar; charset=utf-8" {
+		t.Fatalf("Unexpected response %d: %v", w.Code, w.Header())
+	}
+	if events := strings.Count(w.Body.String(), "BEGIN:VEVENT"); events != 3 {
+		t.Errorf("Expected 3 events, got %d:\n%s", events, w.Body)
+	}
+	if !strings.Contains(w.Body.String(), "TRIGGER:-PT15H") {
+		t.Errorf("Expected a reminder:\n%s", w.Body)
+	}
+
+	if w := get(t, h, "/v1/calendar.ics", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without names, got %d", w.Code)
+	}
+	if w := get(t, h, "/v1/calendar.ics?name=x&remind=soon", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a bad reminder, got %d", w.Code)
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	rosterPath := filepath.Join(t.TempDir(), "team.yaml")
+	if err := os.WriteFile(rosterPath, []byte("people:\n  - name: Пётр\n  - name: Ксения\n    parents: [Иоанн]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := newTestServer(t, testDataset, WithRoster(rosterPath))
+	h := s.Handler()
+
+	w := get(t, h, "/v1/leaderboard?date=2026-03-01", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected response %d: %s", w.Code, w.Body)
+	}
+	var response leaderboardResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Date != "2026-03-01" || len(response.Standings) != 2 || response.Standings[0].Name != "Ксения" ||
+		response.Standings[0].Total != 1.5 || response.Standings[0].TodayGain != 0.5 {
+		t.Errorf("Unexpected leaderboard %+v", response)
+	}
+
+	if w := get(t, h, "/v1/leaderboard?format=csv", nil); w.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Errorf("Unexpected CSV response %v", w.Header())
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/leaderboard?date=2026-01-24", strings.NewReader(`{"people": [{"name": "Ксения"}]}`))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"own_today":true`) {
+		t.Errorf("Unexpected response to a posted roster %d: %s", w.Code, w.Body)
+	}
+
+	// The names parameter of a link to the web page with Ксения
+	link := url.QueryEscape("0JrRgdC10L3QuNGP")
+	if w := get(t, h, "/v1/leaderboard?date=2026-01-24&names="+link, nil); !strings.Contains(w.Body.String(), `"own_today":true`) {
+		t.Errorf("Unexpected response to a roster link %d: %s", w.Code, w.Body)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/v1/leaderboard", strings.NewReader(`{"people": []}`))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an empty roster, got %d", w.Code)
+	}
+
+	// Without a roster file only posted rosters are ranked
+	s, _ = newTestServer(t, testDataset)
+	if w := get(t, s.Handler(), "/v1/leaderboard", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a roster, got %d", w.Code)
+	}
+}
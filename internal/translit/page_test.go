@@ -0,0 +1,96 @@
+package translit
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update data/translit.json")
+
+// pageFile is the file index.html reads its transliteration from
+var pageFile = filepath.Join("..", "..", "data", "translit.json")
+
+// pageTables is the content of pageFile: the tables of this package and
+// samples of Transliterate, Keys and Key that the copy of the page checks
+// itself against
+type pageTables struct {
+	Common     map[string]string            `json:"common"`
+	Schemes    map[Scheme]map[string]string `json:"schemes"`
+	LooseRules [][2]string                  `json:"loose_rules"`
+	Samples    []pageSample                 `json:"samples"`
+	// Keys maps typed Latin spellings to their Key
+	Keys map[string]string `json:"keys"`
+}
+
+type pageSample struct {
+	Name  string            `json:"name"`
+	Latin map[Scheme]string `json:"latin"`
+	Keys  []string          `json:"keys"`
+}
+
+var pageNames = []string{
+	"Ксения", "Фёдор", "Евгений", "Наталья", "Цецилия", "Харитон", "Александр",
+	"Пётр", "Юлия", "Елена", "Савва", "Вячеслав", "Дарья", "Ия", "Эмилия",
+	"Щукин", "Подъячев", "Алексий", "Иоанн Кассиан",
+}
+
+var pageSpellings = []string{
+	"Ksenia", "Kseniya", "KSENIIA", "Xenia", "Aleksandr", "Alexander", "Fjodor",
+	"Yevgeny", "Evgenij", "Julia", "Iuliia", "Yelena", "Peter", "Piotr",
+	"Cecilia", "Philipp", "Sawa", "Tzvetana", "Kassian", "Jean-Yu",
+}
+
+func letterTable(table map[rune]string) map[string]string {
+	result := make(map[string]string, len(table))
+	for r, latin := range table {
+		result[string(r)] = latin
+	}
+	return result
+}
+
+func TestPageTables(t *testing.T) {
+	page := pageTables{
+		Common:     letterTable(common),
+		Schemes:    make(map[Scheme]map[string]string),
+		LooseRules: looseRules,
+		Keys:       make(map[string]string),
+	}
+	for _, scheme := range Schemes {
+		page.Schemes[scheme] = letterTable(tables[scheme])
+	}
+	for _, name := range pageNames {
+		sample := pageSample{Name: name, Latin: make(map[Scheme]string), Keys: Keys(name)}
+		for _, scheme := range Schemes {
+			sample.Latin[scheme] = scheme.Transliterate(name)
+		}
+		page.Samples = append(page.Samples, sample)
+	}
+	for _, spelling := range pageSpellings {
+		page.Keys[spelling] = Key(spelling)
+	}
+
+	actual, err := json.MarshalIndent(page, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to marshal tables: %v", err)
+	}
+	actual = append(actual, '\n')
+
+	if *update {
+		if err := os.WriteFile(pageFile, actual, 0644); err != nil {
+			t.Fatalf("Failed to update %s: %v", pageFile, err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(pageFile)
+	if err != nil {
+		t.Fatalf("Failed to read %s (run with -update to create it): %v", pageFile, err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("%s is out of date, run go test ./internal/translit -update", pageFile)
+	}
+}
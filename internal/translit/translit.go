@@ -0,0 +1,240 @@
+// Package translit writes Cyrillic names in Latin script and matches the
+// Latin spellings people type against them.
+package translit
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+// Scheme is a transliteration system
+type Scheme string
+
+const (
+	// GOST is GOST 7.79-2000 system B, as in Russian library catalogues:
+	// Ксения is Kseniya, Фёдор is Fyodor, Евгений is Evgenij
+	GOST Scheme = "gost"
+	// ICAO is the ICAO Doc 9303 table of Russian passports since 2013:
+	// Ксения is Kseniia, Фёдор is Fedor, Евгений is Evgenii
+	ICAO Scheme = "icao"
+	// Informal is the common everyday spelling: Ксения is Ksenia, Фёдор is
+	// Fyodor, Евгений is Evgeny
+	Informal Scheme = "informal"
+)
+
+// Schemes lists every scheme
+var Schemes = []Scheme{GOST, ICAO, Informal}
+
+// ParseScheme parses a scheme name as given on the command line
+func ParseScheme(s string) (Scheme, error) {
+	switch scheme := Scheme(strings.ToLower(s)); scheme {
+	case GOST, ICAO, Informal:
+		return scheme, nil
+	default:
+		return "", fmt.Errorf("unknown transliteration: %s (expected gost, icao or informal)", s)
+	}
+}
+
+// common holds the letters all schemes write the same way
+var common = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'ч': "ch",
+	'ш': "sh",
+}
+
+var tables = map[Scheme]map[rune]string{
+	GOST: {
+		'ё': "yo", 'й': "j", 'х': "x", 'ц': "cz", 'щ': "shh", 'ъ': "``",
+		'ы': "y`", 'ь': "`", 'э': "e`", 'ю': "yu", 'я': "ya",
+	},
+	ICAO: {
+		'ё': "e", 'й': "i", 'х': "kh", 'ц': "ts", 'щ': "shch", 'ъ': "ie",
+		'ы': "y", 'ь': "", 'э': "e", 'ю': "iu", 'я': "ia",
+	},
+	Informal: {
+		'ё': "yo", 'й': "y", 'х': "kh", 'ц': "ts", 'щ': "shch", 'ъ': "",
+		'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	},
+}
+
+// Transliterate writes s in Latin script. Letters other than Russian
+// Cyrillic are kept, and a capital letter starts a capitalized spelling.
+func (s Scheme) Transliterate(text string) string {
+	runes := []rune(text)
+
+	var b strings.Builder
+	for i, r := range runes {
+		lower := unicode.ToLower(r)
+		latin, ok := s.letter(runes, i, lower)
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if lower != r && latin != "" {
+			first := []rune(latin)
+			first[0] = unicode.ToUpper(first[0])
+			latin = string(first)
+		}
+		b.WriteString(latin)
+	}
+	return b.String()
+}
+
+// letter returns the spelling of the lower case letter r at position i of
+// runes, which the rules of some schemes look around
+func (s Scheme) letter(runes []rune, i int, r rune) (string, bool) {
+	next := func(offset int) rune {
+		if i+offset < len(runes) {
+			return unicode.ToLower(runes[i+offset])
+		}
+		return 0
+	}
+
+	switch {
+	case s == GOST && r == 'ц' && strings.ContainsRune("еиыйэ", next(1)):
+		// GOST writes ц as c before the letters written with e, i, y or j
+		return "c", true
+	case s == Informal && r == 'я' && i > 0 && unicode.ToLower(runes[i-1]) == 'и':
+		// Мария is Maria rather than Mariya
+		return "a", true
+	case s == Informal && r == 'и' && next(1) == 'й' && !unicode.IsLetter(next(2)):
+		// Евгений is Evgeny
+		return "", true
+	}
+
+	if latin, ok := tables[s][r]; ok {
+		return latin, true
+	}
+	latin, ok := common[r]
+	return latin, ok
+}
+
+// IsLatin reports whether text is written in Latin script: it has Latin
+// letters and no Cyrillic ones
+func IsLatin(text string) bool {
+	latin := false
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			return false
+		case unicode.Is(unicode.Latin, r):
+			latin = true
+		}
+	}
+	return latin
+}
+
+// looseRules make the spellings of one name in different schemes, and the
+// ways people type it, equal. At each position the first rule that matches
+// applies.
+var looseRules = [][2]string{
+	{"shh", "shch"},
+	{"cz", "ts"}, {"tz", "ts"},
+	{"ch", "ch"},
+	{"ci", "tsi"}, {"ce", "tse"}, {"cy", "tsy"}, {"cj", "tsj"},
+	{"c", "k"},
+	{"kh", "h"},
+	{"x", "ks"},
+	{"w", "v"},
+	{"ph", "f"},
+	{"q", "k"},
+}
+
+var looseReplacer = func() *strings.Replacer {
+	var oldnew []string
+	for _, rule := range looseRules {
+		oldnew = append(oldnew, rule[0], rule[1])
+	}
+	return strings.NewReplacer(oldnew...)
+}()
+
+// Key returns the comparison key of a Latin spelling. It is the same for
+// the spellings of a name in all schemes and for the usual ways to type
+// it: Ksenia, Kseniya, Kseniia and Xenia share one key, and so do
+// Aleksandr and Alexander. index.html has a copy of Key and Transliterate
+// that reads the tables from data/translit.json and checks itself against
+// the samples there.
+func Key(latin string) string {
+	var letters strings.Builder
+	for _, r := range strings.ToLower(latin) {
+		if r >= 'a' && r <= 'z' {
+			letters.WriteRune(r)
+		}
+	}
+
+	key := looseReplacer.Replace(letters.String())
+	key = strings.NewReplacer("j", "i", "y", "i").Replace(key)
+	// Alexander and Aleksandr, Peter and Petr: the common spellings put a
+	// vowel between the final consonants
+	if n := len(key); n >= 3 && strings.HasSuffix(key, "er") && !strings.ContainsRune("aeiou", rune(key[n-3])) {
+		key = key[:n-2] + "r"
+	}
+	// Yelena and Elena
+	if strings.HasPrefix(key, "ie") {
+		key = key[1:]
+	}
+
+	// Doubled letters come and go: Savva and Sava, Kseniia and Ksenia
+	var b strings.Builder
+	var last rune
+	for _, r := range key {
+		if r != last {
+			b.WriteRune(r)
+		}
+		last = r
+	}
+	return b.String()
+}
+
+// Keys returns the keys of a Cyrillic name in all schemes, without
+// duplicates
+func Keys(name string) []string {
+	var keys []string
+	for _, scheme := range Schemes {
+		key := Key(scheme.Transliterate(name))
+		if key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Namedays returns a copy of namedays with every name and spelling written
+// in Latin script
+func (s Scheme) Namedays(namedays domain.NamedaysDataList) domain.NamedaysDataList {
+	result := make(domain.NamedaysDataList, 0, len(namedays))
+	for _, n := range namedays {
+		latin := domain.NamedaysData{Date: n.Date, OldStyle: n.OldStyle}
+		for _, name := range n.Names {
+			key := s.Transliterate(name)
+			latin.Names = append(latin.Names, key)
+
+			for _, alias := range n.Aliases[name] {
+				if latin.Aliases == nil {
+					latin.Aliases = make(map[string][]string)
+				}
+				latin.Aliases[key] = append(latin.Aliases[key], s.Transliterate(alias))
+			}
+			for _, a := range n.Sources[name] {
+				if latin.Sources == nil {
+					latin.Sources = make(map[string][]domain.Attribution)
+				}
+				a.Spelling = s.Transliterate(a.Spelling)
+				latin.Sources[key] = append(latin.Sources[key], a)
+			}
+			if meta, ok := n.Meta[name]; ok {
+				if latin.Meta == nil {
+					latin.Meta = make(map[string]domain.NameMeta)
+				}
+				latin.Meta[key] = meta
+			}
+		}
+		result = append(result, latin)
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package translit
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		name                 string
+		gost, icao, informal string
+	}{
+		{"Ксения", "Kseniya", "Kseniia", "Ksenia"},
+		{"Фёдор", "Fyodor", "Fedor", "Fyodor"},
+		{"Евгений", "Evgenij", "Evgenii", "Evgeny"},
+		{"Наталья", "Natal`ya", "Natalia", "Natalya"},
+		{"Цецилия", "Ceciliya", "Tsetsiliia", "Tsetsilia"},
+		{"Харитон", "Xariton", "Khariton", "Khariton"},
+		{"Иоанн Кассиан", "Ioann Kassian", "Ioann Kassian", "Ioann Kassian"},
+	}
+
+	for _, tt := range tests {
+		for scheme, expected := range map[Scheme]string{GOST: tt.gost, ICAO: tt.icao, Informal: tt.informal} {
+			if got := scheme.Transliterate(tt.name); got != expected {
+				t.Errorf("%s.Transliterate(%q) = %q, expected %q", scheme, tt.name, got, expected)
+			}
+		}
+	}
+}
+
+func TestKeyMatchesTypedSpellings(t *testing.T) {
+	tests := map[string][]string{
+		"Ксения":    {"Ksenia", "Kseniya", "KSENIIA", "Xenia"},
+		"Александр": {"Aleksandr", "Alexandr", "Alexander"},
+		"Фёдор":     {"Fedor", "Fyodor", "Fjodor"},
+		"Евгений":   {"Evgeniy", "Yevgeny", "Evgenij"},
+		"Юлия":      {"Yulia", "Julia", "Iuliia"},
+		"Елена":     {"Elena", "Yelena"},
+		"Пётр":      {"Petr", "Pyotr", "Piotr", "Peter"},
+		"Цецилия":   {"Tsetsilia", "Cecilia"},
+	}
+
+	for name, typed := range tests {
+		keys := Keys(name)
+		for _, spelling := range typed {
+			if key := Key(spelling); !slices.Contains(keys, key) {
+				t.Errorf("Key(%q) = %q, not among the keys %v of %s", spelling, key, keys, name)
+			}
+		}
+	}
+
+	if keys, other := Keys("Ксения"), Key("Kassian"); slices.Contains(keys, other) {
+		t.Errorf("Kassian matches Ксения")
+	}
+}
+
+func TestIsLatin(t *testing.T) {
+	tests := map[string]bool{
+		"Ksenia":  true,
+		"Ксения":  false,
+		"Kсения":  false,
+		"0124":    false,
+		"Jean-Yu": true,
+	}
+	for text, expected := range tests {
+		if got := IsLatin(text); got != expected {
+			t.Errorf("IsLatin(%q) = %v, expected %v", text, got, expected)
+		}
+	}
+}
+
+func TestParseScheme(t *testing.T) {
+	if scheme, err := ParseScheme("ICAO"); err != nil || scheme != ICAO {
+		t.Errorf("ParseScheme(ICAO) = %q, %v", scheme, err)
+	}
+	if _, err := ParseScheme("bgn"); err == nil {
+		t.Error("Expected an error for an unknown scheme")
+	}
+}
+
+func TestNamedays(t *testing.T) {
+	date := domain.NewDayMonth(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC))
+	namedays := domain.NamedaysDataList{{
+		Date:    date,
+		Names:   []string{"Иван"},
+		Aliases: map[string][]string{"Иван": {"Иоанн"}},
+		Sources: map[string][]domain.Attribution{"Иван": {{Source: "pravmir", Spelling: "Иоанн"}}},
+		Meta:    map[string]domain.NameMeta{"Иван": {Gender: domain.Male}},
+	}}
+
+	expected := domain.NamedaysDataList{{
+		Date:    date,
+		Names:   []string{"Ivan"},
+		Aliases: map[string][]string{"Ivan": {"Ioann"}},
+		Sources: map[string][]domain.Attribution{"Ivan": {{Source: "pravmir", Spelling: "Ioann"}}},
+		Meta:    map[string]domain.NameMeta{"Ivan": {Gender: domain.Male}},
+	}}
+	if got := Informal.Namedays(namedays); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Namedays() = %+v, expected %+v", got, expected)
+	}
+}
@@ -0,0 +1,181 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kvloginov/namedays/internal/domain"
+)
+
+// Severity says whether an issue makes a dataset invalid
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Check names, as they appear in reports
+const (
+	CheckMissingDate   = "missing-date"
+	CheckDuplicateDate = "duplicate-date"
+	CheckEmptyNames    = "empty-names"
+	CheckBadCharacters = "bad-characters"
+	CheckLeftover      = "leftover"
+	CheckLongName      = "long-name"
+	CheckDrop          = "drop"
+)
+
+// Issue is a single problem found in a dataset
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Check    string   `json:"check"`
+	// Date is empty for issues about the whole dataset
+	Date    string `json:"date,omitempty"`
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Date == "" {
+		return fmt.Sprintf("%s [%s] %s", i.Severity, i.Check, i.Message)
+	}
+	return fmt.Sprintf("%s [%s] %s: %s", i.Severity, i.Check, i.Date, i.Message)
+}
+
+// Options tune the checks
+type Options struct {
+	// Previous is the earlier version of the dataset, e.g. the committed
+	// file. Drops are only checked when it is set.
+	Previous domain.NamedaysDataList
+	// MaxNameLength is the longest plausible name in letters
+	MaxNameLength int
+	// MaxDrop is the largest tolerated share of names a date may lose
+	// compared with Previous, from 0 to 1
+	MaxDrop float64
+	// MinDropNames is how many names a date must have had in Previous for
+	// its drop to be checked, so that small dates don't raise noise
+	MinDropNames int
+}
+
+// DefaultOptions returns the options used by the validate command
+func DefaultOptions() Options {
+	return Options{
+		MaxNameLength: 20,
+		MaxDrop:       0.5,
+		MinDropNames:  4,
+	}
+}
+
+// leftovers are phrases the scrapers are supposed to strip from name lists
+var leftovers = []string{"иные", "другие", "др", "др."}
+
+// isLeftover reports whether name is a phrase like "и иные" rather than a name
+func isLeftover(name string) bool {
+	folded := strings.ToLower(strings.TrimSpace(name))
+	if strings.HasPrefix(folded, "и ") {
+		return true
+	}
+	for _, leftover := range leftovers {
+		if folded == leftover {
+			return true
+		}
+	}
+	return false
+}
+
+// Check runs all checks on namedays and returns the issues in date order,
+// dataset-wide issues last
+func Check(namedays domain.NamedaysDataList, opts Options) []Issue {
+	var issues []Issue
+
+	byDate := make(map[domain.DayMonth][]domain.NamedaysData)
+	for _, n := range namedays {
+		byDate[n.Date] = append(byDate[n.Date], n)
+	}
+
+	var previous map[domain.DayMonth]int
+	if opts.Previous != nil {
+		previous = make(map[domain.DayMonth]int)
+		for _, n := range opts.Previous {
+			previous[n.Date] += len(n.Names)
+		}
+	}
+
+	total, previousTotal := 0, 0
+	for _, date := range domain.AllDays() {
+		entries, ok := byDate[date]
+		if !ok {
+			issues = append(issues, Issue{Severity: SeverityError, Check: CheckMissingDate, Date: date.String(), Message: "no entry for " + date.Russian()})
+		}
+		if len(entries) > 1 {
+			issues = append(issues, Issue{Severity: SeverityError, Check: CheckDuplicateDate, Date: date.String(), Message: fmt.Sprintf("%d entries for the same date", len(entries))})
+		}
+
+		count := 0
+		for _, entry := range entries {
+			if len(entry.Names) == 0 {
+				issues = append(issues, Issue{Severity: SeverityError, Check: CheckEmptyNames, Date: date.String(), Message: "empty name list"})
+			}
+			for _, name := range entry.Names {
+				issues = append(issues, checkName(date, name, opts)...)
+			}
+			count += len(entry.Names)
+		}
+
+		total += count
+		if previous != nil {
+			before := previous[date]
+			previousTotal += before
+			if before >= opts.MinDropNames && float64(before-count) > float64(before)*opts.MaxDrop {
+				issues = append(issues, Issue{Severity: SeverityWarning, Check: CheckDrop, Date: date.String(), Message: fmt.Sprintf("%d names, was %d", count, before)})
+			}
+		}
+	}
+
+	if previous != nil && previousTotal > 0 && float64(previousTotal-total) > float64(previousTotal)*opts.MaxDrop {
+		issues = append(issues, Issue{Severity: SeverityError, Check: CheckDrop, Message: fmt.Sprintf("%d names in total, was %d", total, previousTotal)})
+	}
+
+	return issues
+}
+
+func checkName(date domain.DayMonth, name string, opts Options) []Issue {
+	var issues []Issue
+	issue := func(severity Severity, check, format string, args ...any) {
+		issues = append(issues, Issue{Severity: severity, Check: check, Date: date.String(), Message: fmt.Sprintf(format, args...)})
+	}
+
+	if isLeftover(name) {
+		issue(SeverityError, CheckLeftover, "%q is not a name", name)
+		return issues
+	}
+
+	if strings.TrimSpace(name) != name {
+		issue(SeverityError, CheckBadCharacters, "%q has leading or trailing spaces", name)
+	}
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && r != '-' && r != ' ' {
+			issue(SeverityError, CheckBadCharacters, "%q contains %q", name, r)
+			break
+		}
+	}
+
+	if length := utf8.RuneCountInString(name); opts.MaxNameLength > 0 && length > opts.MaxNameLength {
+		issue(SeverityWarning, CheckLongName, "%q is %d letters long", name, length)
+	}
+
+	return issues
+}
+
+// HasErrors reports whether any issue is an error
+func HasErrors(issues []Issue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package validate
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kvloginov/namedays/internal/domain"
+	"github.com/kvloginov/namedays/internal/domain/domaintest"
+)
+
+// fullYear returns a dataset with one clean name on every date
+func fullYear() domain.NamedaysDataList {
+	var list domain.NamedaysDataList
+	for _, date := range domain.AllDays() {
+		list = append(list, domain.NamedaysData{Date: date, Names: []string{"Анна", "Иван", "Пётр", "Мария"}})
+	}
+	return list
+}
+
+func TestCheckClean(t *testing.T) {
+	if issues := Check(fullYear(), DefaultOptions()); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	list := fullYear()
+	// Drop 1 January and duplicate 2 January
+	list[0] = domain.NamedaysData{Date: domaintest.Day(t, time.January, 2), Names: []string{"Сергей"}}
+	list[2].Names = nil
+	list[3].Names = []string{"Иоанн13 января", "Анна (Ганна)", "и иные", "Ирина?", "Жены-мироносицы", "Рождество Господа Бога нашего Иисуса Христа"}
+
+	checks := map[string]int{}
+	for _, issue := range Check(list, DefaultOptions()) {
+		checks[issue.Check]++
+	}
+
+	expected := map[string]int{
+		CheckMissingDate:   1,
+		CheckDuplicateDate: 1,
+		CheckEmptyNames:    1,
+		CheckBadCharacters: 3,
+		CheckLeftover:      1,
+		CheckLongName:      1,
+	}
+	if !reflect.DeepEqual(checks, expected) {
+		t.Errorf("Expected %v, got %v", expected, checks)
+	}
+}
+
+func TestCheckDrop(t *testing.T) {
+	previous := fullYear()
+	list := fullYear()
+	list[10].Names = list[10].Names[:1]
+	list[11].Names = list[11].Names[:2]
+
+	opts := DefaultOptions()
+	opts.Previous = previous
+	issues := Check(list, opts)
+
+	expected := []Issue{{Severity: SeverityWarning, Check: CheckDrop, Date: list[10].Date.String(), Message: "1 names, was 4"}}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Errorf("Expected %v, got %v", expected, issues)
+	}
+	if HasErrors(issues) {
+		t.Error("Expected only a warning for a single date drop")
+	}
+
+	// Losing most names of the whole dataset is an error
+	for i := range list {
+		list[i].Names = list[i].Names[:1]
+	}
+	if !HasErrors(Check(list, opts)) {
+		t.Error("Expected an error for a dataset-wide drop")
+	}
+}